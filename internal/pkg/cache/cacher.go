@@ -15,6 +15,9 @@ type Cacher interface {
 	// Put value into the storage.
 	Put(key string, data []byte) error
 
+	// PutWithTTL puts value into the storage with the custom (not the default) time-to-live.
+	PutWithTTL(key string, data []byte, ttl time.Duration) error
+
 	// Delete value from the storage with passed key.
 	Delete(key string) (bool, error)
 }
@@ -125,7 +125,10 @@ func (c *InMemoryCache) Get(key string) (bool, []byte, time.Duration, error) {
 }
 
 // Put value into the storage.
-func (c *InMemoryCache) Put(key string, data []byte) error {
+func (c *InMemoryCache) Put(key string, data []byte) error { return c.PutWithTTL(key, data, c.ttl) }
+
+// PutWithTTL puts value into the storage with the custom (not the default) time-to-live.
+func (c *InMemoryCache) PutWithTTL(key string, data []byte, ttl time.Duration) error {
 	if c.isClosed() {
 		return ErrClosed
 	}
@@ -137,7 +140,7 @@ func (c *InMemoryCache) Put(key string, data []byte) error {
 	}
 
 	c.storageMu.Lock()
-	c.storage[key] = inmemoryItem{data: data, expiresAtNano: time.Now().Add(c.ttl).UnixNano()}
+	c.storage[key] = inmemoryItem{data: data, expiresAtNano: time.Now().Add(ttl).UnixNano()}
 	c.storageMu.Unlock()
 
 	return nil
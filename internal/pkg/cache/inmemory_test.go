@@ -89,6 +89,27 @@ func TestInMemoryCache_Expiration(t *testing.T) {
 	assert.False(t, found)
 }
 
+func TestInMemoryCache_PutWithTTL(t *testing.T) {
+	cache := NewInMemoryCache(time.Millisecond*50, time.Millisecond)
+	defer func() { assert.NoError(t, cache.Close()) }()
+
+	assert.NoError(t, cache.PutWithTTL("foo", []byte{1, 2, 3}, time.Minute))
+
+	found, data, ttl, err := cache.Get("foo")
+	assert.True(t, found)
+	assert.Equal(t, []byte{1, 2, 3}, data)
+	assert.InDelta(t, time.Minute.Milliseconds(), ttl.Milliseconds(), 10)
+	assert.NoError(t, err)
+
+	<-time.After(time.Millisecond * 60) // default TTL is not applied
+
+	found, _, _, _ = cache.Get("foo") //nolint:dogsled
+	assert.True(t, found)
+
+	assert.ErrorIs(t, cache.PutWithTTL("", []byte{1}, time.Minute), ErrEmptyKey)
+	assert.ErrorIs(t, cache.PutWithTTL("foo", []byte{}, time.Minute), ErrEmptyData)
+}
+
 func TestInMemoryCache_ConcurrentAccess(t *testing.T) {
 	cache := NewInMemoryCache(time.Minute, time.Microsecond)
 
@@ -62,14 +62,17 @@ func (c *RedisCache) Get(key string) (found bool, data []byte, ttl time.Duration
 }
 
 // Put value into the storage.
-func (c *RedisCache) Put(key string, data []byte) error {
+func (c *RedisCache) Put(key string, data []byte) error { return c.PutWithTTL(key, data, c.ttl) }
+
+// PutWithTTL puts value into the storage with the custom (not the default) time-to-live.
+func (c *RedisCache) PutWithTTL(key string, data []byte, ttl time.Duration) error {
 	if key == "" {
 		return ErrEmptyKey
 	} else if len(data) == 0 {
 		return ErrEmptyData
 	}
 
-	return c.redis.Set(c.ctx, c.key(key), data, c.ttl).Err()
+	return c.redis.Set(c.ctx, c.key(key), data, ttl).Err()
 }
 
 // Delete value from the storage with passed key.
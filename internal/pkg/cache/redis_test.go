@@ -48,6 +48,26 @@ func TestRedisCache_GetPutDelete(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestRedisCache_PutWithTTL(t *testing.T) {
+	mini, err := miniredis.Run()
+	assert.NoError(t, err)
+
+	defer mini.Close()
+
+	cache := NewRedisCache(context.Background(), redis.NewClient(&redis.Options{Addr: mini.Addr()}), time.Minute)
+
+	assert.NoError(t, cache.PutWithTTL("foo", []byte{1, 2, 3}, time.Hour))
+
+	found, data, ttl, err := cache.Get("foo")
+	assert.True(t, found)
+	assert.Equal(t, []byte{1, 2, 3}, data)
+	assert.InDelta(t, time.Hour.Milliseconds(), ttl.Milliseconds(), 3)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, cache.PutWithTTL("", []byte{1}, time.Hour), ErrEmptyKey)
+	assert.ErrorIs(t, cache.PutWithTTL("foo", []byte{}, time.Hour), ErrEmptyData)
+}
+
 func TestRedisCache_GetWithEmptyKey(t *testing.T) {
 	cache := NewRedisCache(context.Background(), nil, time.Minute)
 
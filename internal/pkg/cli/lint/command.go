@@ -0,0 +1,160 @@
+// Package lint contains CLI `lint` command implementation.
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"gh.tarampamp.am/mikrotik-hosts-parser/v4/pkg/hostsfile"
+)
+
+const httpClientTimeout = time.Second * 30
+
+// NewCommand creates `lint` command.
+func NewCommand(ctx context.Context) *cobra.Command {
+	var (
+		format       string
+		maxLines     int
+		withComments bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "lint <source-url>",
+		Aliases: []string{"l"},
+		Short:   "Parse the hosts list source and print the report about skipped (rejected) lines",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			f, err := hostsfile.ParseFormat(format)
+			if err != nil {
+				return fmt.Errorf("wrong format [%s]: %w", format, err)
+			}
+
+			in, err := open(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			defer func() { _ = in.Close() }()
+
+			return lint(os.Stdout, in, args[0], f, maxLines, withComments)
+		},
+	}
+
+	cmd.Flags().StringVarP(
+		&format,
+		"format",
+		"f",
+		string(hostsfile.FormatAuto),
+		"source format (auto, hosts, adblock, domains, dnsmasq, rpz)",
+	)
+	cmd.Flags().IntVarP(&maxLines, "max-lines", "n", 100, "maximal skipped lines count to print (0 = without limit)")
+	cmd.Flags().BoolVarP(&withComments, "comments", "c", false, "print comments and empty lines too")
+
+	return cmd
+}
+
+// open opens the source by URL (remote sources) or path (local files).
+func open(ctx context.Context, source string) (io.ReadCloser, error) {
+	if u, err := url.Parse(source); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return os.Open(source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := (&http.Client{Timeout: httpClientTimeout}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		_ = resp.Body.Close()
+
+		return nil, fmt.Errorf("wrong response code: %d", resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+// lint reads the source and writes the lint report.
+func lint(out io.Writer, in io.Reader, source string, f hostsfile.Format, maxLines int, withComments bool) error {
+	var s = hostsfile.NewScanner(in, f)
+
+	s.EnableDiagnostics()
+
+	for s.Next() {
+		_ = s.Record()
+	}
+
+	if err := s.Err(); err != nil {
+		if errors.Is(err, hostsfile.ErrUnsupportedFormat) {
+			return fmt.Errorf("wrong format [%s]: %w", f, err)
+		}
+
+		return fmt.Errorf("source reading error: %w", err)
+	}
+
+	var (
+		d        = s.Diagnostics()
+		detected string
+		reasons  = make([]string, 0, len(d.Counts))
+		b        strings.Builder
+	)
+
+	if f == hostsfile.FormatAuto {
+		detected = " (detected)"
+	}
+
+	for reason := range d.Counts {
+		reasons = append(reasons, string(reason))
+	}
+
+	sort.Strings(reasons)
+
+	fmt.Fprintf(&b, "Source:  %s\n", source)
+	fmt.Fprintf(&b, "Format:  %s%s\n", s.Format(), detected)
+	fmt.Fprintf(&b, "Lines:   %d\n", d.Lines)
+	fmt.Fprintf(&b, "Records: %d\n", d.Records)
+	fmt.Fprintf(&b, "Skipped: %d\n", len(d.Skipped))
+
+	for _, reason := range reasons {
+		fmt.Fprintf(&b, " - %s: %d\n", reason, d.Counts[hostsfile.SkipReason(reason)])
+	}
+
+	var printed int
+
+	for _, line := range d.Skipped {
+		if !withComments && (line.Reason == hostsfile.SkipComment || line.Reason == hostsfile.SkipEmpty) {
+			continue
+		}
+
+		if maxLines > 0 && printed >= maxLines {
+			b.WriteString("...\n")
+
+			break
+		}
+
+		if printed == 0 {
+			b.WriteString("\nSkipped lines:\n")
+		}
+
+		fmt.Fprintf(&b, "%6d: [%s] %s\n", line.Number, line.Reason, line.Content)
+		printed++
+	}
+
+	_, err := io.WriteString(out, b.String())
+
+	return err
+}
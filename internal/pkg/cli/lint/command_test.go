@@ -0,0 +1,103 @@
+package lint
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kami-zh/go-capturer"
+	"github.com/stretchr/testify/assert"
+
+	"gh.tarampamp.am/mikrotik-hosts-parser/v4/pkg/hostsfile"
+)
+
+func TestProperties(t *testing.T) {
+	cmd := NewCommand(context.Background())
+
+	assert.Equal(t, "lint <source-url>", cmd.Use)
+	assert.ElementsMatch(t, []string{"l"}, cmd.Aliases)
+	assert.NotNil(t, cmd.RunE)
+}
+
+func TestCommandRunUsingLocalFile(t *testing.T) {
+	cmd := NewCommand(context.Background())
+	cmd.SetArgs([]string{"../../../../test/testdata/hosts/foo.txt"})
+
+	output := capturer.CaptureStdout(func() {
+		assert.NoError(t, cmd.Execute())
+	})
+
+	assert.Contains(t, output, "Format:  hosts (detected)\n")
+	assert.Contains(t, output, "Records: 9\n")
+	assert.Contains(t, output, " - invalid IP: 3\n")
+	assert.Contains(t, output, "    13: [invalid IP] broken line format\n")
+	assert.NotContains(t, output, "[comment]")
+}
+
+func TestCommandRunUsingRemoteSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("! comment\n||foo.com^\nexample.com##.banner\n"))
+	}))
+	defer srv.Close()
+
+	cmd := NewCommand(context.Background())
+	cmd.SetArgs([]string{"--format", "adblock", "--comments", srv.URL})
+
+	output := capturer.CaptureStdout(func() {
+		assert.NoError(t, cmd.Execute())
+	})
+
+	assert.Contains(t, output, "Format:  adblock\n")
+	assert.Contains(t, output, "Records: 1\n")
+	assert.Contains(t, output, "     1: [comment] ! comment\n")
+	assert.Contains(t, output, "     3: [unsupported syntax] example.com##.banner\n")
+}
+
+func TestCommandRunFailed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	for name, args := range map[string][]string{
+		"wrong response code": {srv.URL},
+		"wrong format":        {"--format", "foo", srv.URL},
+		"file not found":      {"/tmp/this/file/does/not/exist"},
+		"missing argument":    {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cmd := NewCommand(context.Background())
+			cmd.SetArgs(args)
+
+			capturer.CaptureOutput(func() {
+				assert.Error(t, cmd.Execute())
+			})
+		})
+	}
+}
+
+func TestLintMaxLines(t *testing.T) {
+	var (
+		out bytes.Buffer
+		in  = strings.NewReader("# comment\nfoo.com\nb@r.com\nb@z.com\nq x.com\n")
+	)
+
+	assert.NoError(t, lint(&out, in, "foo", hostsfile.FormatDomains, 2, false))
+
+	assert.Equal(t, `Source:  foo
+Format:  domains
+Lines:   5
+Records: 1
+Skipped: 4
+ - comment: 1
+ - invalid hostname: 3
+
+Skipped lines:
+     3: [invalid hostname] b@r.com
+     4: [invalid hostname] b@z.com
+...
+`, out.String())
+}
@@ -8,6 +8,7 @@ import (
 
 	"gh.tarampamp.am/mikrotik-hosts-parser/v4/internal/pkg/checkers"
 	healthcheckCmd "gh.tarampamp.am/mikrotik-hosts-parser/v4/internal/pkg/cli/healthcheck"
+	lintCmd "gh.tarampamp.am/mikrotik-hosts-parser/v4/internal/pkg/cli/lint"
 	serveCmd "gh.tarampamp.am/mikrotik-hosts-parser/v4/internal/pkg/cli/serve"
 	versionCmd "gh.tarampamp.am/mikrotik-hosts-parser/v4/internal/pkg/cli/version"
 	"gh.tarampamp.am/mikrotik-hosts-parser/v4/internal/pkg/logger"
@@ -62,6 +63,7 @@ func NewCommand(appName string) *cobra.Command {
 		versionCmd.NewCommand(version.Version()),
 		serveCmd.NewCommand(ctx, log),
 		healthcheckCmd.NewCommand(checkers.NewHealthChecker(ctx)),
+		lintCmd.NewCommand(ctx),
 	)
 
 	return cmd
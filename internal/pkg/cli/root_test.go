@@ -17,6 +17,7 @@ func TestSubcommands(t *testing.T) {
 	}{
 		{giveName: "serve"},
 		{giveName: "version"},
+		{giveName: "lint"},
 	}
 
 	// get all existing subcommands and put into the map
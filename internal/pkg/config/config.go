@@ -23,6 +23,7 @@ type Config struct {
 		Comment            string `yaml:"comment"`
 		MaxSourcesCount    uint16 `yaml:"max_sources"`
 		MaxSourceSizeBytes uint32 `yaml:"max_source_size"`
+		GenerationsTTLSec  uint32 `yaml:"generations_ttl"` // generations (and zone serials) lifetime (in seconds)
 	} `yaml:"router_script"`
 }
 
@@ -31,7 +32,8 @@ type source struct {
 	Name             string `yaml:"name"`
 	Description      string `yaml:"description"`
 	EnabledByDefault bool   `yaml:"enabled"`
-	RecordsCount     uint   `yaml:"count"` // approximate quantity
+	RecordsCount     uint   `yaml:"count"`  // approximate quantity
+	Format           string `yaml:"format"` // hosts list format (hosts file format is used by default)
 }
 
 // AddSource into sources list.
@@ -50,6 +50,7 @@ sources:
    count: 321
  - uri: http://goo.gl/txt.stsoh
    count: 2
+   format: adblock
 
 router_script:
  redirect:
@@ -61,6 +62,7 @@ router_script:
  comment: " [ blah ] "
  max_sources: 1
  max_source_size: 4
+ generations_ttl: 5
 `),
 			wantErr: false,
 			checkResultFn: func(t *testing.T, config *Config) {
@@ -78,12 +80,14 @@ router_script:
 
 				assert.Equal(t, "http://goo.gl/txt.stsoh", config.Sources[2].URI)
 				assert.Equal(t, uint(2), config.Sources[2].RecordsCount)
+				assert.Equal(t, "adblock", config.Sources[2].Format)
 
 				assert.Equal(t, "0.1.1.0", config.RouterScript.Redirect.Address)
 				assert.ElementsMatch(t, []string{"foo", "bar"}, config.RouterScript.Exclude.Hosts)
 				assert.Equal(t, " [ blah ] ", config.RouterScript.Comment)
 				assert.Equal(t, uint16(1), config.RouterScript.MaxSourcesCount)
 				assert.Equal(t, uint32(4), config.RouterScript.MaxSourceSizeBytes)
+				assert.Equal(t, uint32(5), config.RouterScript.GenerationsTTLSec)
 			},
 		},
 
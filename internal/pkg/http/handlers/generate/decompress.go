@@ -0,0 +1,139 @@
+package generate
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// compressedContentTypes are allowed (in addition to `text/plain`) source content types.
+var compressedContentTypes = map[string]struct{}{ //nolint:gochecknoglobals
+	"application/gzip":             {},
+	"application/x-gzip":           {},
+	"application/zip":              {},
+	"application/x-zip-compressed": {},
+	"application/octet-stream":     {}, // compression is detected using the magic bytes
+}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}           //nolint:gochecknoglobals
+	zipMagic  = []byte{'P', 'K', 0x03, 0x04} //nolint:gochecknoglobals
+)
+
+// errNoTextEntries means that the zip archive does not contain any text file.
+var errNoTextEntries = errors.New("zip archive does not contain text files")
+
+// isAllowedContentType checks the source response content type.
+func isAllowedContentType(ct string) bool {
+	if strings.HasPrefix(ct, "text/plain") {
+		return true
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(ct)
+	_, ok := compressedContentTypes[mediaType]
+
+	return ok
+}
+
+// decompress detects the compressed content (using the Content-Encoding and Content-Type headers or magic bytes)
+// and returns decompressed content. Gzip, deflate and zip (the first text file in the archive) are supported. The
+// decompressed content size is limited by the maxSize (zip bombs protection).
+func decompress(content []byte, header http.Header, maxSize int) ([]byte, error) {
+	var encoding = strings.ToLower(strings.TrimSpace(header.Get("Content-Encoding")))
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+
+	switch {
+	case encoding == "gzip" || encoding == "x-gzip" ||
+		mediaType == "application/gzip" || mediaType == "application/x-gzip" ||
+		bytes.HasPrefix(content, gzipMagic):
+		r, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("gzip: %w", err)
+		}
+
+		defer func() { _ = r.Close() }()
+
+		return readLimited(r, maxSize)
+
+	case encoding == "deflate":
+		if r, err := zlib.NewReader(bytes.NewReader(content)); err == nil { // zlib-wrapped deflate (RFC 1950)
+			defer func() { _ = r.Close() }()
+
+			return readLimited(r, maxSize)
+		}
+
+		var r = flate.NewReader(bytes.NewReader(content)) // raw deflate (RFC 1951), used by some servers
+
+		defer func() { _ = r.Close() }()
+
+		return readLimited(r, maxSize)
+
+	case mediaType == "application/zip" || mediaType == "application/x-zip-compressed" ||
+		bytes.HasPrefix(content, zipMagic):
+		return unzip(content, maxSize)
+	}
+
+	return content, nil // not compressed
+}
+
+// unzip returns the content of the first text file in the zip archive.
+func unzip(content []byte, maxSize int) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("zip: %w", err)
+	}
+
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		r, openErr := file.Open()
+		if openErr != nil {
+			return nil, fmt.Errorf("zip: %w", openErr)
+		}
+
+		data, readErr := readLimited(r, maxSize)
+		_ = r.Close()
+
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		if isText(data) {
+			return data, nil
+		}
+	}
+
+	return nil, errNoTextEntries
+}
+
+// isText checks the beginning of the content for the text data (binary files are skipped).
+func isText(data []byte) bool {
+	return strings.HasPrefix(http.DetectContentType(data), "text/plain")
+}
+
+// readLimited reads all the content from the reader. An error will be returned, if the content size exceeds the limit.
+func readLimited(r io.Reader, maxSize int) ([]byte, error) {
+	var buf bytes.Buffer
+
+	n, err := buf.ReadFrom(io.LimitReader(r, int64(maxSize)))
+	if err != nil {
+		return nil, fmt.Errorf("decompression: %w", err)
+	}
+
+	if int(n) >= maxSize {
+		return nil, &sizeLimitError{what: "decompressed content size", max: maxSize}
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,174 @@
+package generate
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const decompressTestContent = "0.0.0.0 foo.com\n0.0.0.0 bar.com\n"
+
+func compress(t *testing.T, fn func(w io.Writer) io.WriteCloser, content string) []byte {
+	t.Helper()
+
+	var (
+		buf bytes.Buffer
+		w   = fn(&buf)
+	)
+
+	_, err := w.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, files ...[2]string) []byte {
+	t.Helper()
+
+	var (
+		buf bytes.Buffer
+		w   = zip.NewWriter(&buf)
+	)
+
+	for _, file := range files {
+		f, err := w.Create(file[0])
+		assert.NoError(t, err)
+
+		_, err = f.Write([]byte(file[1]))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	var (
+		gzipped  = compress(t, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }, decompressTestContent)
+		zlibbed  = compress(t, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }, decompressTestContent)
+		deflated = compress(t, func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+
+			return fw
+		}, decompressTestContent)
+	)
+
+	for name, tt := range map[string]struct {
+		giveContent []byte
+		giveHeader  http.Header
+		wantContent string
+		wantError   string
+	}{
+		"plain text": {
+			giveContent: []byte(decompressTestContent),
+			giveHeader:  http.Header{"Content-Type": {"text/plain"}},
+			wantContent: decompressTestContent,
+		},
+		"gzip magic bytes": {
+			giveContent: gzipped,
+			giveHeader:  http.Header{"Content-Type": {"text/plain"}},
+			wantContent: decompressTestContent,
+		},
+		"gzip content type": {
+			giveContent: gzipped,
+			giveHeader:  http.Header{"Content-Type": {"application/gzip"}},
+			wantContent: decompressTestContent,
+		},
+		"gzip content encoding": {
+			giveContent: gzipped,
+			giveHeader:  http.Header{"Content-Encoding": {"gzip"}},
+			wantContent: decompressTestContent,
+		},
+		"broken gzip": {
+			giveContent: []byte("foo"),
+			giveHeader:  http.Header{"Content-Type": {"application/x-gzip"}},
+			wantError:   "gzip:",
+		},
+		"zlib deflate": {
+			giveContent: zlibbed,
+			giveHeader:  http.Header{"Content-Encoding": {"deflate"}},
+			wantContent: decompressTestContent,
+		},
+		"raw deflate": {
+			giveContent: deflated,
+			giveHeader:  http.Header{"Content-Encoding": {"Deflate"}},
+			wantContent: decompressTestContent,
+		},
+		"zip first text file": {
+			giveContent: zipArchive(t,
+				[2]string{"dir/", ""},
+				[2]string{"image.png", "\x89PNG\r\n\x1a\n\x00\x00\x00"},
+				[2]string{"hosts.txt", decompressTestContent},
+				[2]string{"another.txt", "0.0.0.0 baz.com\n"},
+			),
+			giveHeader:  http.Header{"Content-Type": {"application/zip"}},
+			wantContent: decompressTestContent,
+		},
+		"zip without text files": {
+			giveContent: zipArchive(t, [2]string{"image.png", "\x89PNG\r\n\x1a\n\x00\x00\x00"}),
+			giveHeader:  http.Header{"Content-Type": {"application/octet-stream"}},
+			wantError:   "does not contain text files",
+		},
+		"broken zip": {
+			giveContent: []byte("foo"),
+			giveHeader:  http.Header{"Content-Type": {"application/zip"}},
+			wantError:   "zip:",
+		},
+		"gzip bomb": {
+			giveContent: compress(t, func(w io.Writer) io.WriteCloser {
+				return gzip.NewWriter(w)
+			}, strings.Repeat("0.0.0.0 foo.com\n", 1024)),
+			giveHeader: http.Header{},
+			wantError:  "decompressed content size is too big (max: 1024)",
+		},
+		"zip bomb": {
+			giveContent: zipArchive(t, [2]string{"hosts.txt", strings.Repeat("0.0.0.0 foo.com\n", 1024)}),
+			giveHeader:  http.Header{},
+			wantError:   "decompressed content size is too big (max: 1024)",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			content, err := decompress(tt.giveContent, tt.giveHeader, 1024)
+
+			if tt.wantError != "" {
+				assert.ErrorContains(t, err, tt.wantError)
+				assert.Nil(t, content)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantContent, string(content))
+		})
+	}
+}
+
+func TestIsAllowedContentType(t *testing.T) {
+	for ct, want := range map[string]bool{
+		"text/plain":                    true,
+		"text/plain; charset=utf-8":     true,
+		"application/gzip":              true,
+		"application/x-gzip":            true,
+		"application/zip":               true,
+		"application/x-zip-compressed":  true,
+		"application/octet-stream":      true,
+		"Application/Octet-Stream; q=1": true,
+		"text/html":                     false,
+		"application/json":              false,
+		"":                              false,
+	} {
+		t.Run(ct, func(t *testing.T) {
+			assert.Equal(t, want, isAllowedContentType(ct))
+		})
+	}
+}
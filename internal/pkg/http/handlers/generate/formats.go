@@ -0,0 +1,530 @@
+package generate
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"hash/fnv"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/idna"
+
+	"gh.tarampamp.am/mikrotik-hosts-parser/v4/pkg/hostsfile"
+	"gh.tarampamp.am/mikrotik-hosts-parser/v4/pkg/mikrotik"
+)
+
+const (
+	formatRouterOS = "routeros" //nolint:misspell
+	formatHosts    = "hosts"
+	formatDnsmasq  = "dnsmasq"
+	formatUnbound  = "unbound"
+	formatRPZ      = "rpz"
+	formatJSON     = "json"
+	formatCSV      = "csv"
+
+	formatAddressList = "address-list" // RouterOS firewall address list
+)
+
+const (
+	idnPunycode = "punycode" // internationalized domain names are rendered in the ASCII form (A-labels, `xn--...`)
+	idnUnicode  = "unicode"  // internationalized domain names are rendered in the Unicode form
+)
+
+const (
+	defaultAddressList   = "blocked" // default firewall address list name
+	maxAddressListLength = 63        // maximal firewall address list name length
+)
+
+const (
+	updateAppend  = "append"  // script contains the `add` commands only
+	updateReplace = "replace" // managed (with the script comment) entries are removed before the adding
+	updateGuard   = "guard"   // entries are added only if they do not exist (by the name or regular expression)
+)
+
+const (
+	modeRedirect = "redirect" // resolve blocked hosts into the redirection IP address
+	modeNXDomain = "nxdomain" // answer with NXDOMAIN for the blocked hosts
+)
+
+// renderer renders the merged (sorted and de-duplicated) hosts list in some output format.
+type renderer interface {
+	// ContentType returns the value for the "Content-Type" response header.
+	ContentType() string
+
+	// Comment writes comment lines into the writer.
+	Comment(w io.Writer, comments ...string)
+
+	// Render writes the hosts list entries into the writer.
+	Render(w io.Writer, entries []hostEntry) error
+}
+
+// zoneRenderer is a renderer of the DNS zone files. Zone serial number must increase on every zone content change
+// (RFC 1982 serial arithmetic is used by the secondary servers), so it is tracked by the handler.
+type zoneRenderer interface {
+	renderer
+
+	// ContentHash returns the zone content hash (the serial number must be changed only when it changes).
+	ContentHash(entries []hostEntry) string
+
+	// SetSerial sets the zone serial number.
+	SetSerial(serial uint32)
+}
+
+// newRenderer creates renderer for the requested output format. False will be returned for unsupported formats.
+func newRenderer(p *reqParams, comment string) (renderer, bool) {
+	switch p.format {
+	case formatRouterOS:
+		return &routerOSRenderer{
+			redirect:         p.redirect.String(),
+			comment:          comment,
+			update:           p.update,
+			nxdomain:         p.mode == modeNXDomain,
+			upstreamComments: p.upstreamComments,
+		}, true
+
+	case formatAddressList:
+		return &addressListRenderer{comment: comment, list: p.list, timeout: p.timeout}, true
+
+	case formatHosts:
+		return &hostsRenderer{redirect: p.redirect.String()}, true
+
+	case formatDnsmasq:
+		return &dnsmasqRenderer{redirect: p.redirect.String(), nxdomain: p.mode == modeNXDomain}, true
+
+	case formatUnbound:
+		return &unboundRenderer{
+			redirect:   p.redirect.String(),
+			recordType: addressRecordType(p.redirect),
+			nxdomain:   p.mode == modeNXDomain,
+		}, true
+
+	case formatRPZ:
+		return &rpzRenderer{
+			redirect:   p.redirect.String(),
+			recordType: addressRecordType(p.redirect),
+			nxdomain:   p.mode == modeNXDomain,
+		}, true
+
+	case formatJSON:
+		return &jsonRenderer{redirect: p.redirect.String()}, true
+
+	case formatCSV:
+		return &csvRenderer{redirect: p.redirect.String()}, true
+	}
+
+	return nil, false
+}
+
+// addressRecordType returns DNS record type (A or AAAA) for the IP address.
+func addressRecordType(ip net.IP) string {
+	if ip.To4() == nil {
+		return "AAAA"
+	}
+
+	return "A"
+}
+
+// toUnicode converts internationalized domain names (A-labels) of the entries into the Unicode form.
+func toUnicode(entries []hostEntry) {
+	for i := range entries {
+		if !strings.Contains(entries[i].name, "xn--") {
+			continue
+		}
+
+		if name, err := idna.Display.ToUnicode(entries[i].name); err == nil {
+			entries[i].name = name
+		}
+	}
+}
+
+// hashComments writes comments in format `## comment text`.
+type hashComments struct{}
+
+func (hashComments) Comment(w io.Writer, comments ...string) {
+	for i := range comments {
+		_, _ = w.Write([]byte("## " + comments[i] + "\n"))
+	}
+}
+
+// noComments ignores any comments (for the formats without comments support).
+type noComments struct{}
+
+func (noComments) Comment(io.Writer, ...string) {}
+
+// semicolonComments writes comments in format `;; comment text` (DNS zone files comments style).
+type semicolonComments struct{}
+
+func (semicolonComments) Comment(w io.Writer, comments ...string) {
+	for i := range comments {
+		_, _ = w.Write([]byte(";; " + comments[i] + "\n"))
+	}
+}
+
+// routerOSRenderer renders RouterOS script with static DNS entries.
+type routerOSRenderer struct {
+	hashComments
+
+	redirect, comment string
+	update            string // script update mode (append, replace or guard)
+	nxdomain          bool   // `type=NXDOMAIN` entries instead of the redirection (RouterOS v7+)
+	upstreamComments  bool   // entries comments contain the upstream comments (after the script comment)
+
+	previous map[string]string // previous generation entries (line to the `find` filter), see routerOSRenderer.Since
+}
+
+func (*routerOSRenderer) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (r *routerOSRenderer) Render(w io.Writer, entries []hostEntry) error {
+	var static, removed = r.static(entries), []string(nil)
+
+	if r.previous != nil { // incremental script
+		static, removed = r.diff(static)
+	}
+
+	_, _ = w.Write([]byte("\n/ip dns static\n"))
+
+	if r.update == updateReplace {
+		_, _ = w.Write([]byte("remove [find " + r.managedFilter() + "]\n"))
+	}
+
+	for i := range removed {
+		_, _ = w.Write([]byte("remove [find " + removed[i] + "]\n"))
+	}
+
+	_, err := static.Render(w, r.renderingOptions())
+	_, _ = w.Write([]byte("\n\n"))
+
+	return err
+}
+
+func (r *routerOSRenderer) renderingOptions() mikrotik.RenderingOptions {
+	return mikrotik.RenderingOptions{Prefix: "add", Escape: true, Guard: r.update == updateGuard}
+}
+
+// static converts the entries into the RouterOS static DNS entries.
+func (r *routerOSRenderer) static(entries []hostEntry) mikrotik.DNSStaticEntries {
+	var static = make(mikrotik.DNSStaticEntries, 0, len(entries))
+
+	for i := range entries {
+		var entry = mikrotik.DNSStaticEntry{
+			Comment:        r.entryComment(&entries[i]),
+			MatchSubdomain: entries[i].matchSubdomain,
+			Name:           entries[i].name,
+			Regexp:         entries[i].regexp,
+		}
+
+		if r.nxdomain {
+			entry.Type = mikrotik.TypeNXDOMAIN
+		} else {
+			entry.Address = r.redirect
+		}
+
+		static = append(static, entry)
+	}
+
+	return static
+}
+
+// managedFilter returns the `find` command filter for the entries, managed by the script (entries with the script
+// comment, optionally followed by the upstream comment).
+func (r *routerOSRenderer) managedFilter() string {
+	var filter = `comment="` + mikrotik.Escape(r.comment) + `"`
+
+	if r.upstreamComments {
+		filter = `where ` + filter + ` or comment~"` + mikrotik.Escape("^"+regexp.QuoteMeta(r.comment)+": ") + `"`
+	}
+
+	return filter
+}
+
+// entryComment returns the comment for the entry. Upstream comment (if exists) is appended to the script comment
+// (special characters are escaped by the entries renderer).
+func (r *routerOSRenderer) entryComment(e *hostEntry) string {
+	switch {
+	case e.comment == "":
+		return r.comment
+	case r.comment == "":
+		return e.comment
+	}
+
+	return r.comment + ": " + e.comment
+}
+
+// addressListRenderer renders RouterOS script with firewall address list entries (RouterOS resolves the hostnames).
+type addressListRenderer struct {
+	hashComments
+
+	comment, list, timeout string
+}
+
+func (*addressListRenderer) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (r *addressListRenderer) Render(w io.Writer, entries []hostEntry) error {
+	var list = make(mikrotik.FirewallAddressList, 0, len(entries))
+
+	for i := range entries {
+		list = append(list, mikrotik.FirewallAddressListEntry{
+			Address: entries[i].name,
+			Comment: r.comment,
+			List:    r.list,
+			Timeout: r.timeout,
+		})
+	}
+
+	_, _ = w.Write([]byte("\n/ip firewall address-list\n"))
+	_, err := list.Render(w, mikrotik.RenderingOptions{Prefix: "add", Escape: true})
+	_, _ = w.Write([]byte("\n\n"))
+
+	return err
+}
+
+// hostsRenderer renders classic hosts file (`/etc/hosts` syntax).
+type hostsRenderer struct {
+	hashComments
+
+	redirect string
+}
+
+func (*hostsRenderer) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (r *hostsRenderer) Render(w io.Writer, entries []hostEntry) error {
+	var records = make([]hostsfile.Record, len(entries))
+
+	for i := range entries {
+		records[i] = hostsfile.Record{IP: r.redirect, Host: entries[i].name}
+	}
+
+	if _, err := w.Write([]byte("\n")); err != nil {
+		return err
+	}
+
+	if err := hostsfile.Write(w, records); err != nil {
+		return err
+	}
+
+	_, err := w.Write([]byte("\n"))
+
+	return err
+}
+
+// dnsmasqRenderer renders dnsmasq configuration (`address=/example.com/0.0.0.0` or `local=/example.com/`).
+type dnsmasqRenderer struct {
+	hashComments
+
+	redirect string
+	nxdomain bool
+}
+
+func (*dnsmasqRenderer) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (r *dnsmasqRenderer) Render(w io.Writer, entries []hostEntry) error {
+	var buf = make([]byte, 0, 64)
+
+	if _, err := w.Write([]byte("\n")); err != nil {
+		return err
+	}
+
+	for i := range entries {
+		if r.nxdomain {
+			buf = append(buf[:0], "local=/"...)
+			buf = append(buf, entries[i].name...)
+			buf = append(buf, "/\n"...)
+		} else {
+			buf = append(buf[:0], "address=/"...)
+			buf = append(buf, entries[i].name...)
+			buf = append(buf, '/')
+			buf = append(buf, r.redirect...)
+			buf = append(buf, '\n')
+		}
+
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write([]byte("\n"))
+
+	return err
+}
+
+// unboundRenderer renders unbound configuration (`local-zone` and `local-data` entries inside the `server:` clause).
+type unboundRenderer struct {
+	hashComments
+
+	redirect   string
+	recordType string // A or AAAA (depends on the redirect IP address version)
+	nxdomain   bool
+}
+
+func (*unboundRenderer) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (r *unboundRenderer) Render(w io.Writer, entries []hostEntry) error {
+	var buf = make([]byte, 0, 128)
+
+	if _, err := w.Write([]byte("\nserver:\n")); err != nil {
+		return err
+	}
+
+	for i := range entries {
+		buf = append(buf[:0], `  local-zone: "`...)
+		buf = append(buf, entries[i].name...)
+
+		if r.nxdomain {
+			buf = append(buf, "\" always_nxdomain\n"...)
+		} else {
+			buf = append(buf, "\" redirect\n"...)
+			buf = append(buf, `  local-data: "`...)
+			buf = append(buf, entries[i].name...)
+			buf = append(buf, ' ')
+			buf = append(buf, r.recordType...)
+			buf = append(buf, ' ')
+			buf = append(buf, r.redirect...)
+			buf = append(buf, "\"\n"...)
+		}
+
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write([]byte("\n"))
+
+	return err
+}
+
+// rpzRenderer renders DNS Response Policy Zone (RPZ) file (can be used with BIND, PowerDNS Recursor, etc.).
+type rpzRenderer struct {
+	semicolonComments
+
+	redirect   string
+	recordType string // A or AAAA (depends on the redirect IP address version)
+	nxdomain   bool
+	serial     uint32 // zone serial number (see zoneRenderer)
+}
+
+func (*rpzRenderer) ContentType() string { return "text/dns" }
+
+func (r *rpzRenderer) Render(w io.Writer, entries []hostEntry) error {
+	var buf = make([]byte, 0, 128)
+
+	// SOA and NS records are required for the zone loading, but never used for the policy matching
+	buf = append(buf, "\n$TTL 300\n@ IN SOA localhost. root.localhost. ("...)
+	buf = strconv.AppendUint(buf, uint64(r.serial), 10)
+	buf = append(buf, " 3600 600 86400 300)\n  IN NS localhost.\n\n"...)
+
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+
+	for i := range entries {
+		buf = append(buf[:0], entries[i].name...)
+
+		if r.nxdomain {
+			buf = append(buf, " CNAME .\n"...) // the "NXDOMAIN" policy action
+		} else {
+			buf = append(buf, ' ')
+			buf = append(buf, r.recordType...)
+			buf = append(buf, ' ')
+			buf = append(buf, r.redirect...)
+			buf = append(buf, '\n')
+		}
+
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write([]byte("\n"))
+
+	return err
+}
+
+// ContentHash returns the zone content hash (hex-encoded 64-bit FNV-1a hash). The zone serial number must be changed
+// only when the hash changes, so zone transfers are not triggered without any reason.
+func (r *rpzRenderer) ContentHash(entries []hostEntry) string {
+	var h = fnv.New64a()
+
+	_, _ = h.Write([]byte(r.recordType + " " + r.redirect + " " + strconv.FormatBool(r.nxdomain) + "\n"))
+
+	for i := range entries {
+		_, _ = h.Write([]byte(entries[i].name))
+		_, _ = h.Write([]byte{'\n'})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SetSerial sets the zone serial number.
+func (r *rpzRenderer) SetSerial(serial uint32) { r.serial = serial }
+
+// jsonRenderer renders JSON array with hosts list entries (comments are not supported).
+type jsonRenderer struct {
+	noComments
+
+	redirect string
+}
+
+func (*jsonRenderer) ContentType() string { return "application/json; charset=utf-8" }
+
+type jsonEntry struct {
+	Name    string   `json:"name"`
+	Address string   `json:"address"`
+	Sources []string `json:"sources"`
+}
+
+func (r *jsonRenderer) Render(w io.Writer, entries []hostEntry) error {
+	if _, err := w.Write([]byte("[")); err != nil {
+		return err
+	}
+
+	for i := range entries {
+		raw, err := json.Marshal(jsonEntry{Name: entries[i].name, Address: r.redirect, Sources: entries[i].sources})
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			raw = append([]byte(",\n"), raw...)
+		}
+
+		if _, err = w.Write(raw); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write([]byte("]\n"))
+
+	return err
+}
+
+// csvRenderer renders CSV table with hosts list entries (comments are not supported). Source URLs are separated
+// by the space character.
+type csvRenderer struct {
+	noComments
+
+	redirect string
+}
+
+func (*csvRenderer) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (r *csvRenderer) Render(w io.Writer, entries []hostEntry) error {
+	var c = csv.NewWriter(w)
+
+	if err := c.Write([]string{"name", "address", "sources"}); err != nil {
+		return err
+	}
+
+	for i := range entries {
+		if err := c.Write([]string{entries[i].name, r.redirect, strings.Join(entries[i].sources, " ")}); err != nil {
+			return err
+		}
+	}
+
+	c.Flush()
+
+	return c.Error()
+}
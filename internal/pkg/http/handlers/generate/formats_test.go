@@ -0,0 +1,293 @@
+package generate
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRenderer(t *testing.T) {
+	for _, tt := range []struct {
+		giveFormat string
+		wantOk     bool
+	}{
+		{giveFormat: formatRouterOS, wantOk: true},
+		{giveFormat: formatHosts, wantOk: true},
+		{giveFormat: formatDnsmasq, wantOk: true},
+		{giveFormat: formatUnbound, wantOk: true},
+		{giveFormat: formatRPZ, wantOk: true},
+		{giveFormat: formatJSON, wantOk: true},
+		{giveFormat: formatCSV, wantOk: true},
+		{giveFormat: formatAddressList, wantOk: true},
+		{giveFormat: "foobar", wantOk: false},
+		{giveFormat: "", wantOk: false},
+	} {
+		t.Run(tt.giveFormat, func(t *testing.T) {
+			p := newReqParams(net.IPv4(127, 0, 0, 1))
+			p.format = tt.giveFormat
+
+			r, ok := newRenderer(&p, "foo")
+
+			assert.Equal(t, tt.wantOk, ok)
+
+			if tt.wantOk {
+				assert.NotNil(t, r)
+			} else {
+				assert.Nil(t, r)
+			}
+		})
+	}
+}
+
+func TestRouterOSRenderer_Render(t *testing.T) {
+	for name, tt := range map[string]struct {
+		giveRenderer routerOSRenderer
+		wantResult   string
+	}{
+		"redirect": {
+			giveRenderer: routerOSRenderer{redirect: "127.0.0.1", comment: "foo"},
+			wantResult: "\n/ip dns static\n" +
+				"add address=127.0.0.1 comment=\"foo\" disabled=no name=\"a.com\"\n" +
+				"add address=127.0.0.1 comment=\"foo\" disabled=no name=\"b.com\"\n\n",
+		},
+		"nxdomain": {
+			giveRenderer: routerOSRenderer{redirect: "127.0.0.1", comment: "foo", nxdomain: true},
+			wantResult: "\n/ip dns static\n" +
+				"add comment=\"foo\" disabled=no name=\"a.com\" type=NXDOMAIN\n" +
+				"add comment=\"foo\" disabled=no name=\"b.com\" type=NXDOMAIN\n\n",
+		},
+		"replace": {
+			giveRenderer: routerOSRenderer{redirect: "127.0.0.1", comment: "foo", update: updateReplace},
+			wantResult: "\n/ip dns static\n" +
+				"remove [find comment=\"foo\"]\n" +
+				"add address=127.0.0.1 comment=\"foo\" disabled=no name=\"a.com\"\n" +
+				"add address=127.0.0.1 comment=\"foo\" disabled=no name=\"b.com\"\n\n",
+		},
+		"replace with upstream comments": {
+			giveRenderer: routerOSRenderer{
+				redirect:         "127.0.0.1",
+				comment:          "foo.bar",
+				update:           updateReplace,
+				upstreamComments: true,
+			},
+			wantResult: "\n/ip dns static\n" +
+				`remove [find where comment="foo.bar" or comment~"^foo\\.bar: "]` + "\n" +
+				"add address=127.0.0.1 comment=\"foo.bar\" disabled=no name=\"a.com\"\n" +
+				"add address=127.0.0.1 comment=\"foo.bar\" disabled=no name=\"b.com\"\n\n",
+		},
+		"guard": {
+			giveRenderer: routerOSRenderer{redirect: "127.0.0.1", comment: "foo", update: updateGuard},
+			wantResult: "\n/ip dns static\n" +
+				":if ([:len [find name=\"a.com\"]] = 0) do={add address=127.0.0.1 comment=\"foo\" disabled=no " +
+				"name=\"a.com\"}\n" +
+				":if ([:len [find name=\"b.com\"]] = 0) do={add address=127.0.0.1 comment=\"foo\" disabled=no " +
+				"name=\"b.com\"}\n\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			assert.NoError(t, tt.giveRenderer.Render(&buf, newTestEntries("a.com", "b.com")))
+			assert.Equal(t, tt.wantResult, buf.String())
+		})
+	}
+}
+
+func TestHostsRenderer_Render(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		r   = hostsRenderer{redirect: "::1"}
+	)
+
+	assert.NoError(t, r.Render(&buf, newTestEntries("a.com", "b.com")))
+	assert.Equal(t, "\n::1 a.com\n::1 b.com\n\n", buf.String())
+	assert.Equal(t, "text/plain; charset=utf-8", r.ContentType())
+}
+
+func TestDnsmasqRenderer_Render(t *testing.T) {
+	for name, tt := range map[string]struct {
+		giveRenderer dnsmasqRenderer
+		wantResult   string
+	}{
+		"redirect": {
+			giveRenderer: dnsmasqRenderer{redirect: "0.0.0.0"},
+			wantResult:   "\naddress=/a.com/0.0.0.0\naddress=/b.com/0.0.0.0\n\n",
+		},
+		"nxdomain": {
+			giveRenderer: dnsmasqRenderer{redirect: "0.0.0.0", nxdomain: true},
+			wantResult:   "\nlocal=/a.com/\nlocal=/b.com/\n\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			assert.NoError(t, tt.giveRenderer.Render(&buf, newTestEntries("a.com", "b.com")))
+			assert.Equal(t, tt.wantResult, buf.String())
+		})
+	}
+}
+
+func TestUnboundRenderer_Render(t *testing.T) {
+	for name, tt := range map[string]struct {
+		giveRedirect net.IP
+		giveMode     string
+		wantResult   string
+	}{
+		"redirect to IPv4": {
+			giveRedirect: net.IPv4(0, 0, 0, 0),
+			giveMode:     modeRedirect,
+			wantResult: "\nserver:\n" +
+				"  local-zone: \"a.com\" redirect\n  local-data: \"a.com A 0.0.0.0\"\n" +
+				"  local-zone: \"b.com\" redirect\n  local-data: \"b.com A 0.0.0.0\"\n\n",
+		},
+		"redirect to IPv6": {
+			giveRedirect: net.IPv6loopback,
+			giveMode:     modeRedirect,
+			wantResult: "\nserver:\n" +
+				"  local-zone: \"a.com\" redirect\n  local-data: \"a.com AAAA ::1\"\n" +
+				"  local-zone: \"b.com\" redirect\n  local-data: \"b.com AAAA ::1\"\n\n",
+		},
+		"nxdomain": {
+			giveRedirect: net.IPv4(0, 0, 0, 0),
+			giveMode:     modeNXDomain,
+			wantResult: "\nserver:\n" +
+				"  local-zone: \"a.com\" always_nxdomain\n" +
+				"  local-zone: \"b.com\" always_nxdomain\n\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var (
+				buf bytes.Buffer
+				p   = newReqParams(tt.giveRedirect)
+			)
+
+			p.format, p.mode = formatUnbound, tt.giveMode
+
+			r, ok := newRenderer(&p, "")
+			assert.True(t, ok)
+
+			assert.NoError(t, r.Render(&buf, newTestEntries("a.com", "b.com")))
+			assert.Equal(t, tt.wantResult, buf.String())
+		})
+	}
+}
+
+func TestRPZRenderer_Render(t *testing.T) {
+	for name, tt := range map[string]struct {
+		giveRenderer rpzRenderer
+		wantRecords  string
+	}{
+		"redirect": {
+			giveRenderer: rpzRenderer{redirect: "0.0.0.0", recordType: "A"},
+			wantRecords:  "a.com A 0.0.0.0\nb.com A 0.0.0.0\n",
+		},
+		"nxdomain": {
+			giveRenderer: rpzRenderer{redirect: "0.0.0.0", recordType: "A", nxdomain: true},
+			wantRecords:  "a.com CNAME .\nb.com CNAME .\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			assert.NoError(t, tt.giveRenderer.Render(&buf, newTestEntries("a.com", "b.com")))
+
+			assert.Regexp(t, `(?m)^\$TTL \d+$`, buf.String())
+			assert.Regexp(t, `(?m)^@ IN SOA localhost\. root\.localhost\. \(\d+ \d+ \d+ \d+ \d+\)$`, buf.String())
+			assert.Regexp(t, `(?m)^  IN NS localhost\.$`, buf.String())
+			assert.Contains(t, buf.String(), "\n\n"+tt.wantRecords+"\n")
+		})
+	}
+}
+
+func TestRPZRenderer_ContentHash(t *testing.T) {
+	var r = rpzRenderer{redirect: "0.0.0.0", recordType: "A"}
+
+	assert.Equal(t, r.ContentHash(newTestEntries("a.com", "b.com")), r.ContentHash(newTestEntries("a.com", "b.com")))
+	assert.NotEqual(t, r.ContentHash(newTestEntries("a.com", "b.com")), r.ContentHash(newTestEntries("a.com", "c.com")))
+	assert.NotEqual(t, r.ContentHash(newTestEntries("a.com", "b.com")), r.ContentHash(newTestEntries("a.com")))
+
+	var nx = rpzRenderer{redirect: "0.0.0.0", recordType: "A", nxdomain: true}
+
+	assert.NotEqual(t, r.ContentHash(newTestEntries("a.com")), nx.ContentHash(newTestEntries("a.com")))
+
+	var buf bytes.Buffer
+
+	r.SetSerial(1234567890)
+	assert.NoError(t, r.Render(&buf, nil))
+	assert.Contains(t, buf.String(), "@ IN SOA localhost. root.localhost. (1234567890 ")
+}
+
+func TestRPZRenderer_Comment(t *testing.T) {
+	var buf bytes.Buffer
+
+	(&rpzRenderer{}).Comment(&buf, "foo", "bar")
+
+	assert.Equal(t, ";; foo\n;; bar\n", buf.String())
+}
+
+func newTestEntries(names ...string) []hostEntry {
+	var entries = make([]hostEntry, 0, len(names))
+
+	for _, name := range names {
+		entries = append(entries, hostEntry{name: name})
+	}
+
+	return entries
+}
+
+func TestJSONRenderer_Render(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		r   = jsonRenderer{redirect: "0.0.0.0"}
+	)
+
+	assert.NoError(t, r.Render(&buf, []hostEntry{
+		{name: "a.com", sources: []string{"http://foo/1.txt"}},
+		{name: "b.com", sources: []string{"http://foo/1.txt", "http://foo/2.txt"}},
+	}))
+	assert.JSONEq(t, `[
+		{"name": "a.com", "address": "0.0.0.0", "sources": ["http://foo/1.txt"]},
+		{"name": "b.com", "address": "0.0.0.0", "sources": ["http://foo/1.txt", "http://foo/2.txt"]}
+	]`, buf.String())
+
+	buf.Reset()
+
+	assert.NoError(t, r.Render(&buf, nil))
+	assert.JSONEq(t, `[]`, buf.String())
+
+	buf.Reset()
+
+	r.Comment(&buf, "foo")
+	assert.Empty(t, buf.String())
+}
+
+func TestCSVRenderer_Render(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		r   = csvRenderer{redirect: "0.0.0.0"}
+	)
+
+	assert.NoError(t, r.Render(&buf, []hostEntry{
+		{name: "a.com", sources: []string{"http://foo/1.txt"}},
+		{name: "b.com", sources: []string{"http://foo/1.txt", "http://foo/2.txt"}},
+	}))
+	assert.Equal(t, "name,address,sources\n"+
+		"a.com,0.0.0.0,http://foo/1.txt\n"+
+		"b.com,0.0.0.0,http://foo/1.txt http://foo/2.txt\n", buf.String())
+	assert.Equal(t, "text/csv; charset=utf-8", r.ContentType())
+}
+
+func TestAddressListRenderer_Render(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		r   = addressListRenderer{comment: "foo", list: "blocked", timeout: "1d"}
+	)
+
+	assert.NoError(t, r.Render(&buf, []hostEntry{{name: "a.com"}, {name: "b.com"}}))
+	assert.Equal(t, "\n/ip firewall address-list\n"+
+		"add address=\"a.com\" comment=\"foo\" disabled=no list=\"blocked\" timeout=1d\n"+
+		"add address=\"b.com\" comment=\"foo\" disabled=no list=\"blocked\" timeout=1d\n\n", buf.String())
+	assert.Equal(t, "text/plain; charset=utf-8", r.ContentType())
+}
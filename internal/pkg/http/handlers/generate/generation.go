@@ -0,0 +1,161 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+
+	"gh.tarampamp.am/mikrotik-hosts-parser/v4/pkg/mikrotik"
+)
+
+// incrementalRenderer is a renderer, that supports the incremental scripts (only the difference with the previous
+// script generation is rendered).
+type incrementalRenderer interface {
+	renderer
+
+	// Generation returns the generation hash of the entries and the generation data (for the storing in the cache).
+	Generation(entries []hostEntry) (hash string, data []byte)
+
+	// Since sets the previous generation data. Only the difference with it will be rendered.
+	Since(data []byte) error
+
+	// Replace switches the renderer to the full script, that removes all the managed entries before the adding (it is
+	// used when the previous generation is unknown, so the vanished entries cannot be removed one by one).
+	Replace()
+}
+
+// generationHashLength is the length of the generation hash (hex-encoded 64-bit FNV-1a hash).
+const generationHashLength = 16
+
+// defaultGenerationsTTL is the generations lifetime, used when it is not configured.
+const defaultGenerationsTTL = time.Hour * 24 * 7
+
+// generationCacheKey returns the cache key for the generation data.
+func generationCacheKey(hash string) string { return "generation:" + hash }
+
+// isGenerationHash checks the generation hash format.
+func isGenerationHash(s string) bool {
+	if len(s) != generationHashLength {
+		return false
+	}
+
+	_, err := strconv.ParseUint(s, 16, 64)
+
+	return err == nil
+}
+
+var errWrongGeneration = errors.New("wrong generation data")
+
+// Generation returns the generation hash and data (`find` filter and the rendered entry line per line, tab-separated).
+func (r *routerOSRenderer) Generation(entries []hostEntry) (string, []byte) {
+	var (
+		static = r.static(entries)
+		data   = make([]byte, 0, len(static)*64)
+		h      = fnv.New64a()
+	)
+
+	for i := range static {
+		line, ok := r.line(&static[i])
+		if !ok {
+			continue
+		}
+
+		_, _ = h.Write([]byte(line + "\n"))
+
+		data = append(data, entryFilter(&static[i])...)
+		data = append(data, '\t')
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), data
+}
+
+// Since sets the previous generation data (see routerOSRenderer.Generation).
+func (r *routerOSRenderer) Since(data []byte) error {
+	var previous = make(map[string]string)
+
+	for len(data) > 0 {
+		var line []byte
+
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i], data[i+1:]
+		} else {
+			line, data = data, nil
+		}
+
+		filter, entry, ok := bytes.Cut(line, []byte{'\t'})
+		if !ok || len(filter) == 0 || len(entry) == 0 {
+			return errWrongGeneration
+		}
+
+		previous[string(entry)] = string(filter)
+	}
+
+	r.previous = previous
+
+	return nil
+}
+
+// Replace switches the renderer to the [replace] update mode (see incrementalRenderer.Replace).
+func (r *routerOSRenderer) Replace() { r.update, r.previous = updateReplace, nil }
+
+// diff returns the entries, that were not present in the previous generation, and the `find` filters for the
+// entries, that are not present in the current one (sorted).
+func (r *routerOSRenderer) diff(static mikrotik.DNSStaticEntries) (mikrotik.DNSStaticEntries, []string) {
+	var (
+		added   = make(mikrotik.DNSStaticEntries, 0)
+		current = make(map[string]struct{}, len(static))
+		removed = make([]string, 0)
+	)
+
+	for i := range static {
+		line, ok := r.line(&static[i])
+		if !ok {
+			continue
+		}
+
+		current[line] = struct{}{}
+
+		if _, exists := r.previous[line]; !exists {
+			added = append(added, static[i])
+		}
+	}
+
+	for line, filter := range r.previous {
+		if _, exists := current[line]; !exists {
+			removed = append(removed, filter)
+		}
+	}
+
+	sort.Strings(removed)
+
+	return added, removed
+}
+
+// line renders the entry (without the prefix and guards). False will be returned for the invalid entries.
+func (*routerOSRenderer) line(entry *mikrotik.DNSStaticEntry) (string, bool) {
+	var buf bytes.Buffer
+
+	if n, _ := (mikrotik.DNSStaticEntries{*entry}).Render(&buf, mikrotik.RenderingOptions{Escape: true}); n == 0 {
+		return "", false
+	}
+
+	return buf.String(), true
+}
+
+// entryFilter returns the `find` command filter for the entry (by the name or regular expression). The filter is
+// scoped by the entry comment, so the hand-made entries with the same name are never matched.
+func entryFilter(entry *mikrotik.DNSStaticEntry) string {
+	var filter = `comment="` + mikrotik.Escape(entry.Comment) + `" `
+
+	if entry.Name != "" {
+		return filter + `name="` + mikrotik.Escape(entry.Name) + `"`
+	}
+
+	return filter + `regexp="` + mikrotik.Escape(entry.Regexp) + `"`
+}
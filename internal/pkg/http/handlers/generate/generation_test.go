@@ -0,0 +1,73 @@
+package generate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterOSRenderer_Generation(t *testing.T) {
+	var r = routerOSRenderer{redirect: "127.0.0.1", comment: "foo"}
+
+	hash, data := r.Generation([]hostEntry{{name: "a.com"}, {regexp: `^(a|b)\.b\.com$`}, {name: `c"d.com`}})
+
+	assert.Len(t, hash, generationHashLength)
+	assert.True(t, isGenerationHash(hash))
+	assert.Equal(t, ""+
+		`comment="foo" name="a.com"`+"\t"+`address=127.0.0.1 comment="foo" disabled=no name="a.com"`+"\n"+
+		`comment="foo" regexp="^(a|b)\\.b\\.com\$"`+"\t"+
+		`address=127.0.0.1 comment="foo" disabled=no regexp="^(a|b)\\.b\\.com\$"`+"\n"+
+		`comment="foo" name="c\"d.com"`+"\t"+`address=127.0.0.1 comment="foo" disabled=no name="c\"d.com"`+"\n",
+		string(data),
+	)
+
+	sameHash, _ := r.Generation([]hostEntry{{name: "a.com"}, {regexp: `^(a|b)\.b\.com$`}, {name: `c"d.com`}})
+	assert.Equal(t, hash, sameHash)
+
+	otherHash, _ := (&routerOSRenderer{redirect: "0.0.0.0", comment: "foo"}).Generation([]hostEntry{{name: "a.com"}})
+	assert.NotEqual(t, hash, otherHash)
+
+	// the filter is scoped by the whole entry comment (with the upstream one)
+	_, data = (&routerOSRenderer{redirect: "127.0.0.1", comment: "foo", upstreamComments: true}).
+		Generation([]hostEntry{{name: "a.com", comment: "bar"}})
+	assert.True(t, strings.HasPrefix(string(data), `comment="foo: bar" name="a.com"`+"\t"), string(data))
+}
+
+func TestRouterOSRenderer_Since(t *testing.T) {
+	var (
+		previous = routerOSRenderer{redirect: "127.0.0.1", comment: "foo"}
+		r        = routerOSRenderer{redirect: "127.0.0.1", comment: "foo", update: updateGuard}
+		buf      bytes.Buffer
+	)
+
+	_, data := previous.Generation([]hostEntry{{name: "a.com"}, {name: "b.com"}, {name: "c.com"}})
+
+	assert.NoError(t, r.Since(data))
+	assert.NoError(t, r.Render(&buf, []hostEntry{{name: "b.com"}, {name: "c.com", matchSubdomain: true}, {name: "d.com"}}))
+	assert.Equal(t, "\n/ip dns static\n"+
+		`remove [find comment="foo" name="a.com"]`+"\n"+
+		`remove [find comment="foo" name="c.com"]`+"\n"+
+		`:if ([:len [find name="c.com"]] = 0) do={add address=127.0.0.1 comment="foo" disabled=no `+
+		`match-subdomain=yes name="c.com"}`+"\n"+
+		`:if ([:len [find name="d.com"]] = 0) do={add address=127.0.0.1 comment="foo" disabled=no name="d.com"}`+
+		"\n\n",
+		buf.String(),
+	)
+
+	assert.ErrorIs(t, r.Since([]byte("foo\n")), errWrongGeneration)
+}
+
+func TestIsGenerationHash(t *testing.T) {
+	for give, want := range map[string]bool{
+		"0123456789abcdef":  true,
+		"0123456789ABCDEF":  true,
+		"0123456789abcde":   false,
+		"0123456789abcdef0": false,
+		"0123456789abcdeg":  false,
+		"":                  false,
+	} {
+		assert.Equal(t, want, isGenerationHash(give), give)
+	}
+}
@@ -14,22 +14,23 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"go.uber.org/zap"
+	"golang.org/x/net/idna"
 
 	"gh.tarampamp.am/mikrotik-hosts-parser/v4/internal/pkg/cache"
 	"gh.tarampamp.am/mikrotik-hosts-parser/v4/internal/pkg/config"
 	"gh.tarampamp.am/mikrotik-hosts-parser/v4/internal/pkg/version"
 	"gh.tarampamp.am/mikrotik-hosts-parser/v4/pkg/hostsfile"
-	"gh.tarampamp.am/mikrotik-hosts-parser/v4/pkg/mikrotik"
 )
 
 type metrics interface {
 	IncrementCacheHits()
 	IncrementCacheMisses()
 	ObserveGenerationDuration(time.Duration)
+	IncrementOversizedSources()
 }
 
 type handler struct {
@@ -40,6 +41,7 @@ type handler struct {
 	m      metrics
 
 	defaultRedirectIP net.IP
+	sourceFormats     map[string]hostsfile.Format // formats of the sources, defined in the config
 
 	httpClient interface {
 		Do(*http.Request) (*http.Response, error)
@@ -49,7 +51,6 @@ type handler struct {
 const (
 	httpClientTimeout      = time.Second * 10
 	httpClientMaxRedirects = 2
-	formatRouterOS         = "routeros" //nolint:misspell
 )
 
 // NewHandler creates RouterOS script generation handler.
@@ -60,10 +61,6 @@ func NewHandler(
 	cfg *config.Config,
 	m metrics,
 ) (http.Handler, error) {
-	if containsIllegalSymbols(cfg.RouterScript.Comment) {
-		return nil, errors.New("wrong config: script comment contains illegal symbols")
-	}
-
 	if cfg.RouterScript.MaxSourcesCount <= 0 {
 		return nil, errors.New("wrong config: max sources count")
 	}
@@ -84,6 +81,17 @@ func NewHandler(
 		m:      m,
 
 		httpClient: &http.Client{Timeout: httpClientTimeout, CheckRedirect: checkRedirectFn},
+
+		sourceFormats: make(map[string]hostsfile.Format, len(cfg.Sources)),
+	}
+
+	for _, source := range cfg.Sources {
+		f, err := hostsfile.ParseFormat(source.Format)
+		if err != nil {
+			return nil, fmt.Errorf("wrong config: source <%s> format [%s]: %w", source.URI, source.Format, err)
+		}
+
+		h.sourceFormats[source.URI] = f
 	}
 
 	if ip := net.ParseIP(cfg.RouterScript.Redirect.Address); ip != nil {
@@ -97,7 +105,7 @@ func NewHandler(
 
 type hostsFileData struct {
 	url      string
-	records  []hostsfile.Record
+	content  []byte
 	cacheHit bool
 	cacheTTL time.Duration
 	err      error
@@ -128,95 +136,71 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) { //nolint:f
 		return
 	}
 
-	if format := params.format; format != formatRouterOS {
+	if params.update == updateReplace && h.cfg.RouterScript.Comment == "" {
 		w.WriteHeader(http.StatusBadRequest)
-		h.writeComment(w, fmt.Sprintf("Unsupported format [%s] requested", format))
+		h.writeComment(w, "Update mode ["+updateReplace+"] requires the script comment (see the configuration file)")
 
 		return
 	}
 
-	// write script header
-	h.writeComment(w,
-		"Script generated at "+time.Now().Format("2006-01-02 15:04:05"),
-		"Generator version: "+version.Version(),
-		fmt.Sprintf("Limit: %d", params.limit),
-		fmt.Sprintf("Cache lifetime: %s", h.cacher.TTL().Round(time.Second)),
-		"Format: "+params.format,
-		"Redirect to: "+params.redirect.String(),
-		"Sources list:",
-	)
+	// the incremental script removes the vanished entries, so it must distinguish them from the hand-made entries
+	if params.incremental && h.cfg.RouterScript.Comment == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		h.writeComment(w, "Incremental script requires the script comment (see the configuration file)")
 
-	for i := 0; i < len(params.sources); i++ {
-		h.writeComment(w, fmt.Sprintf(" - <%s>", params.sources[i]))
+		return
 	}
 
-	if len(params.excluded) > 0 {
-		h.writeComment(w, "Excluded hosts:")
+	rnd, ok := newRenderer(&params, h.cfg.RouterScript.Comment)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		h.writeComment(w, fmt.Sprintf("Unsupported format [%s] requested", params.format))
 
-		for i := 0; i < len(params.excluded); i++ {
-			h.writeComment(w, fmt.Sprintf(" - %s", params.excluded[i]))
-		}
+		return
 	}
 
 	var (
-		hostsDataCh       = make(chan hostsFileData, len(params.sources))
-		hostsRecordsCount uint32 // atomic usage only, used for hosts list pre-allocation
-		wg                sync.WaitGroup
+		hostsData = make([]hostsFileData, len(params.sources)) // indexes are the same as for the sources list
+		wg        sync.WaitGroup
 	)
 
 	wg.Add(len(params.sources))
 
-	// fetch hosts files content and parse them
+	// fetch hosts files content
 	for i := 0; i < len(params.sources); i++ {
-		go func(ch chan<- hostsFileData, url string) {
+		go func(data *hostsFileData, url string) {
 			defer wg.Done()
 
-			if hit, data, ttl, err := h.cacher.Get(url); hit && err == nil {
-				records, parsingErr := hostsfile.Parse(bytes.NewReader(data))
-				if parsingErr == nil {
-					//nolint:gosec // bounded by source size and used only for preallocation
-					atomic.AddUint32(&hostsRecordsCount, uint32(len(records)))
-
-					ch <- hostsFileData{url: url, records: records, cacheHit: hit, cacheTTL: ttl}
+			data.url = url
 
-					return
-				}
-
-				ch <- hostsFileData{url: url, cacheHit: hit, err: parsingErr}
+			if hit, content, ttl, err := h.cacher.Get(url); hit && err == nil {
+				data.content, data.cacheHit, data.cacheTTL = content, hit, ttl
 
 				return
 			}
 
-			data, srcErr := h.fetchRemoteSource(url)
+			content, srcErr := h.fetchRemoteSource(url)
 			if srcErr != nil {
 				h.log.Warn("remote source fetching failed", zap.Error(srcErr), zap.String("url", url))
 
-				ch <- hostsFileData{url: url, err: srcErr}
+				data.err = srcErr
 
 				return
 			}
 
-			if err := h.cacher.Put(url, data.Bytes()); err != nil {
+			if err := h.cacher.Put(url, content.Bytes()); err != nil {
 				h.log.Error("cache writing error", zap.Error(err), zap.String("url", url))
 
-				ch <- hostsFileData{url: url, err: err}
+				data.err = err
 
 				return
 			}
 
-			if records, err := hostsfile.Parse(data); err == nil {
-				//nolint:gosec // bounded by source size and used only for preallocation
-				atomic.AddUint32(&hostsRecordsCount, uint32(len(records)))
-
-				ch <- hostsFileData{url: url, records: records, cacheTTL: h.cacher.TTL()}
-			} else {
-				ch <- hostsFileData{url: url, err: err}
-			}
-		}(hostsDataCh, params.sources[i])
+			data.content, data.cacheTTL = content.Bytes(), h.cacher.TTL()
+		}(&hostsData[i], params.sources[i])
 	}
 
 	wg.Wait()
-	close(hostsDataCh)
 
 	if err := h.ctx.Err(); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -225,28 +209,174 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) { //nolint:f
 		return
 	}
 
-	// burn excludes map for fastest checking
-	var excludes = make(map[string]struct{}, len(params.excluded))
-	for i := 0; i < len(params.excluded); i++ {
-		excludes[params.excluded[i]] = struct{}{}
+	var hostNames = newHostsSet(int(params.limit), params.excluded, hostsSetOptions{
+		trackSources:  params.format == formatJSON || params.format == formatCSV,
+		trackComments: params.upstreamComments && params.format == formatRouterOS,
+		collapseWWW:   params.collapseWWW,
+	})
+
+	// parse hosts files content (record by record) and merge them
+	var report = h.merge(hostsData, hostNames)
+
+	var (
+		result = hostNames.sorted()
+		saved  int // entries, covered by the parent domains ("match subdomain" flag) or regular expressions
+	)
+
+	if params.matchSubdomain {
+		result, saved = hostNames.collapseSubdomains(result)
 	}
 
-	// calculate results map size for pre-allocation
-	var size uint32
-	if params.limit > 0 {
-		size = params.limit
-	} else {
-		size = atomic.LoadUint32(&hostsRecordsCount)
+	if params.idn == idnUnicode {
+		toUnicode(result)
 	}
 
-	var hostNames, limit = make(map[string]struct{}, size), int(size)
+	if params.regexpCompaction {
+		result, saved = compactRegexps(result)
+	}
+
+	for i := range report.errors { // per-source errors are reported for the formats without comments support too
+		w.Header().Add(sourceErrorHeader, report.errors[i])
+	}
+
+	var total = len(result) // entries count before the chunking
+
+	// the empty list is not rendered for the formats without comments (consumers cannot distinguish it from the real
+	// empty list) and for the scripts, that remove the entries (e.g. with the "replace" update mode - all the entries
+	// would be removed)
+	if total == 0 && (params.format == formatJSON || params.format == formatCSV || params.removing() != "") {
+		var status = http.StatusUnprocessableEntity
+
+		if len(report.errors) > 0 {
+			status = http.StatusBadGateway
+		}
+
+		h.fail(w, status, "Script generation failed (empty hosts list)", report.notes)
+
+		return
+	}
+
+	// the replace and incremental scripts would remove the entries of the failed sources from the router
+	if feature := params.removing(); feature != "" && len(report.errors) > 0 {
+		h.fail(w, http.StatusBadGateway, "Script generation failed (all the sources must be available for the "+
+			feature+")", report.notes)
+
+		return
+	}
+
+	var pages int // chunks count
+
+	if params.chunkSize > 0 {
+		result, pages = chunk(result, int(params.chunk), int(params.chunkSize))
+
+		// the chunks count is available for the formats without comments too
+		w.Header().Set(chunksTotalHeader, strconv.Itoa(pages))
+
+		// the empty chunk past the last one cannot be distinguished from the empty list otherwise
+		if int(params.chunk) > pages {
+			h.fail(w, http.StatusRequestedRangeNotSatisfiable,
+				fmt.Sprintf("Chunk %d is out of range (chunks count: %d)", params.chunk, pages), report.notes)
+
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", rnd.ContentType())
+
+	// write script header
+	rnd.Comment(w,
+		"Script generated at "+time.Now().Format("2006-01-02 15:04:05"),
+		"Generator version: "+version.Version(),
+		fmt.Sprintf("Limit: %d", params.limit),
+		fmt.Sprintf("Cache lifetime: %s", h.cacher.TTL().Round(time.Second)),
+		"Format: "+params.format,
+		"Mode: "+params.mode,
+		"Update: "+params.update,
+		"Redirect to: "+params.redirect.String(),
+		"Sources list:",
+	)
 
-	// read parsed hosts files content from channel
 	for i := 0; i < len(params.sources); i++ {
-		data := <-hostsDataCh
+		rnd.Comment(w, fmt.Sprintf(" - <%s>", params.sources[i]))
+	}
+
+	if len(params.excluded) > 0 {
+		rnd.Comment(w, "Excluded hosts:")
+
+		for i := 0; i < len(params.excluded); i++ {
+			rnd.Comment(w, fmt.Sprintf(" - %s", params.excluded[i]))
+		}
+	}
+
+	rnd.Comment(w, report.notes...)
+
+	if params.chunkSize > 0 {
+		rnd.Comment(w, fmt.Sprintf("Chunk: %d of %d (chunk size: %d)", params.chunk, pages, params.chunkSize))
+	}
+
+	if inc, isIncremental := rnd.(incrementalRenderer); isIncremental && params.incremental {
+		h.incremental(w, inc, result, params.since)
+	}
+
+	if zone, isZone := rnd.(zoneRenderer); isZone {
+		zone.SetSerial(h.zoneSerial(zone.ContentHash(result)))
+	}
+
+	if renderingErr := rnd.Render(w, result); renderingErr != nil {
+		rnd.Comment(w, fmt.Sprintf("Script rendering error: %v", renderingErr))
+	}
+
+	rnd.Comment(w, fmt.Sprintf(
+		"Records count: %d (%d records ignored)",
+		total,
+		report.records-total,
+	))
+	rnd.Comment(w, fmt.Sprintf("Duplicates collapsed: %d", hostNames.collapsed))
+
+	if params.matchSubdomain {
+		rnd.Comment(w, fmt.Sprintf("Entries saved by the subdomains matching: %d", saved))
+	}
+
+	if params.regexpCompaction {
+		rnd.Comment(w, fmt.Sprintf("Entries saved by the regexp compaction: %d", saved))
+	}
+
+	generationDuration := time.Since(startedAt)
+	rnd.Comment(w, fmt.Sprintf("Generated in %s", generationDuration))
+	h.m.ObserveGenerationDuration(generationDuration)
+}
+
+// sourceErrorHeader is the response header with the source error (one header per failed source).
+const sourceErrorHeader = "X-Source-Error"
+
+// chunksTotalHeader is the response header with the chunks count (for the chunked responses).
+const chunksTotalHeader = "X-Chunks-Total"
+
+// mergeReport describes the sources merging result.
+type mergeReport struct {
+	notes   []string // sources processing comments (cache usage, formats, errors)
+	errors  []string // failed sources errors (`<url>: error`)
+	records int      // records count in the successfully parsed sources
+}
+
+// merge parses hosts files content (record by record) and merges them into the hosts set.
+func (h *handler) merge(hostsData []hostsFileData, hostNames *hostsSet) mergeReport {
+	var report = mergeReport{notes: make([]string, 0, len(hostsData)*2)}
+
+	var fail = func(url string, err error) {
+		report.notes = append(report.notes, fmt.Sprintf("Source <%s> error: %v", url, err))
+		report.errors = append(report.errors, fmt.Sprintf("<%s>: %v", url, err))
+	}
+
+	for i := 0; i < len(hostsData); i++ {
+		data := &hostsData[i]
 
 		if data.err != nil {
-			h.writeComment(w, fmt.Sprintf("Source <%s> error: %v", data.url, data.err))
+			if tooBig := new(sizeLimitError); errors.As(data.err, &tooBig) {
+				h.m.IncrementOversizedSources()
+			}
+
+			fail(data.url, data.err)
 
 			continue
 		}
@@ -254,90 +384,200 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) { //nolint:f
 		if data.cacheHit {
 			h.m.IncrementCacheHits()
 
-			h.writeComment(w, fmt.Sprintf("Cache HIT for <%s> (expires after %s)", data.url, data.cacheTTL.Round(time.Second)))
+			report.notes = append(report.notes,
+				fmt.Sprintf("Cache HIT for <%s> (expires after %s)", data.url, data.cacheTTL.Round(time.Second)),
+			)
 		} else {
 			h.m.IncrementCacheMisses()
 
-			h.writeComment(w, fmt.Sprintf("Cache miss for <%s>", data.url))
+			report.notes = append(report.notes, fmt.Sprintf("Cache miss for <%s>", data.url))
 		}
 
-	recordsLoop:
-		for j := 0; j < len(data.records); j++ { // loop over records inside hosts file
-			if name := data.records[j].Host; name != "" {
-				if len(hostNames) >= limit { // hostnames limit has been reached
-					break recordsLoop
-				}
+		format, ok := h.sourceFormats[data.url]
+		if !ok {
+			format = hostsfile.FormatAuto
+		}
 
-				if !containsIllegalSymbols(name) {
-					if _, ok := excludes[name]; !ok { // is in excludes list?
-						hostNames[name] = struct{}{} // append
-					}
-				}
-			}
+		// records are merged only if the whole source can be parsed, so the content is scanned twice (validation pass
+		// discards the records) instead of the records buffering
+		if err := validateSource(data.content, format); err != nil {
+			fail(data.url, err)
 
-			if len(data.records[j].AdditionalHosts) > 0 { //nolint:nestif
-				for k := 0; k < len(data.records[j].AdditionalHosts); k++ { // loop over additional hostnames
-					if len(hostNames) >= limit { // hostnames limit has been reached
-						break recordsLoop
-					}
+			continue
+		}
 
-					name := data.records[j].AdditionalHosts[k]
+		var scanner, count = hostsfile.NewScanner(bytes.NewReader(data.content), format), 0
 
-					if _, ok := excludes[name]; ok { // is in excludes list?
-						continue
-					}
+		for ; scanner.Next(); count++ {
+			var rec = scanner.Record()
 
-					if !containsIllegalSymbols(name) {
-						if _, ok := excludes[name]; !ok { // is in excludes list?
-							hostNames[name] = struct{}{} // append
-						}
-					}
-				}
-			}
+			hostNames.addRecord(&rec, data.url)
+		}
+
+		report.records += count
+
+		var detected string
+		if format == hostsfile.FormatAuto {
+			detected = " (detected)"
+		}
+
+		report.notes = append(report.notes,
+			fmt.Sprintf("Source <%s> format: %s%s, records: %d", data.url, scanner.Format(), detected, count),
+		)
+	}
+
+	return report
+}
+
+// isRouterOSTime checks the RouterOS time value format (`1w2d3h4m5s` with any units set or `01:30:00`).
+func isRouterOSTime(s string) bool {
+	if hours, rest, ok := strings.Cut(s, ":"); ok {
+		minutes, seconds, _ := strings.Cut(rest, ":")
+
+		return isDigits(hours) && len(minutes) == 2 && isDigits(minutes) && len(seconds) == 2 && isDigits(seconds)
+	}
+
+	const units = "wdhms" // units must be in this order
+
+	var digits, next = 0, 0 // digits count before the unit, the next allowed unit index
+
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= '0' && c <= '9' {
+			digits++
+
+			continue
+		}
+
+		idx := strings.IndexByte(units[next:], s[i])
+		if idx < 0 || digits == 0 {
+			return false
+		}
+
+		digits, next = 0, next+idx+1
+	}
+
+	return s != "" && digits == 0
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
 		}
 	}
 
-	if len(hostNames) == 0 {
-		h.writeComment(w, "Script generation failed (empty hosts list)")
+	return s != ""
+}
+
+// chunk returns the entries chunk (numbers start from 1) and the total chunks count (at least one). Empty slice will
+// be returned for the non-existing chunk number.
+func chunk(entries []hostEntry, number, size int) ([]hostEntry, int) {
+	var total = max(1, (len(entries)+size-1)/size)
+
+	if number < 1 || number > total {
+		return entries[:0], total
+	}
+
+	var from = (number - 1) * size
+
+	return entries[from:min(from+size, len(entries))], total
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
 
+	return true
+}
+
+// incremental stores the entries generation in the cache (with its own lifetime, see the configuration file) and (if
+// the previous generation hash is passed) configures the renderer for the incremental script rendering. If the
+// previous generation is not found, the renderer is switched to the [replace] update mode.
+func (h *handler) incremental(w io.Writer, rnd incrementalRenderer, entries []hostEntry, since string) {
+	hash, data := rnd.Generation(entries)
+
+	if len(data) > 0 {
+		if err := h.cacher.PutWithTTL(generationCacheKey(hash), data, h.generationsTTL()); err != nil {
+			h.log.Error("generation caching error", zap.Error(err), zap.String("hash", hash))
+		}
+	}
+
+	rnd.Comment(w, "Generation: "+hash+" (pass it as the `since` query parameter for the incremental script)")
+
+	if since == "" {
 		return
 	}
 
-	var result, redirectAddr = make(mikrotik.DNSStaticEntries, 0, len(hostNames)), params.redirect.String()
-	for hostName := range hostNames {
-		result = append(result, mikrotik.DNSStaticEntry{
-			Address: redirectAddr,
-			Comment: h.cfg.RouterScript.Comment,
-			Name:    hostName,
-		})
+	if hit, previous, _, err := h.cacher.Get(generationCacheKey(since)); hit && err == nil {
+		if sinceErr := rnd.Since(previous); sinceErr == nil {
+			rnd.Comment(w, "Incremental script since the generation "+since)
+
+			return
+		}
 	}
 
-	// make sorting
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].Name < result[j].Name
-	})
+	// the router already contains the entries of the unknown generation, so they must be replaced (the script comment
+	// is required for the incremental scripts, so the managed entries can be always found)
+	rnd.Replace()
+	rnd.Comment(w, "Generation "+since+" was not found (expired?), full script (with the managed entries "+
+		"replacement) generated")
+}
+
+// generationsTTL returns the lifetime of the stored generations state (incremental script generations and zone serial
+// numbers). It is not related to the cache TTL, so the state outlives the cached sources.
+func (h *handler) generationsTTL() time.Duration {
+	if ttl := time.Duration(h.cfg.RouterScript.GenerationsTTLSec) * time.Second; ttl > 0 {
+		return ttl
+	}
+
+	return defaultGenerationsTTL
+}
 
-	_, _ = w.Write([]byte("\n/ip dns static\n"))
-	_, renderingErr := result.Render(w, mikrotik.RenderingOptions{Prefix: "add"})
-	_, _ = w.Write([]byte("\n\n"))
+// zoneSerialCacheKey returns the cache key for the zone serial number of the content with passed hash.
+func zoneSerialCacheKey(hash string) string { return "zone-serial:" + hash }
 
-	if renderingErr != nil {
-		h.writeComment(w, fmt.Sprintf("Script rendering error: %v", renderingErr))
+// zoneSerial returns the zone serial number for the zone content with passed hash. The serial is the (unix) time of
+// the first content generation, stored in the cache (for the generations lifetime), so it is the same for the same
+// content and increases when the content changes.
+func (h *handler) zoneSerial(hash string) uint32 {
+	if serial, ok := h.cachedZoneSerial(zoneSerialCacheKey(hash)); ok {
+		return serial
 	}
 
-	h.writeComment(w, fmt.Sprintf(
-		"Records count: %d (%d records ignored)",
-		len(result),
-		int(atomic.LoadUint32(&hostsRecordsCount))-len(result),
-	))
+	var serial = uint32(time.Now().Unix()) //nolint:gosec // unix time fits into uint32 until 2106
 
-	generationDuration := time.Since(startedAt)
-	h.writeComment(w, fmt.Sprintf("Generated in %s", generationDuration))
-	h.m.ObserveGenerationDuration(generationDuration)
+	// the serial must increase, even if the content was changed more than once per second
+	if last, ok := h.cachedZoneSerial(zoneSerialCacheKey("last")); ok && last >= serial {
+		serial = last + 1
+	}
+
+	for _, key := range []string{zoneSerialCacheKey(hash), zoneSerialCacheKey("last")} {
+		if err := h.cacher.PutWithTTL(key, strconv.AppendUint(nil, uint64(serial), 10), h.generationsTTL()); err != nil {
+			h.log.Error("zone serial caching error", zap.Error(err), zap.String("key", key))
+		}
+	}
+
+	return serial
+}
+
+func (h *handler) cachedZoneSerial(key string) (uint32, bool) {
+	if hit, data, _, err := h.cacher.Get(key); hit && err == nil {
+		if serial, parsingErr := strconv.ParseUint(string(data), 10, 32); parsingErr == nil {
+			return uint32(serial), true
+		}
+	}
+
+	return 0, false
 }
 
-func containsIllegalSymbols(s string) bool {
-	return strings.ContainsRune(s, '"') || strings.ContainsRune(s, '\\')
+// fail writes the response status and comments (without the script).
+func (h *handler) fail(w http.ResponseWriter, status int, reason string, notes []string) {
+	w.WriteHeader(status)
+	h.writeComment(w, reason)
+	h.writeComment(w, notes...)
 }
 
 func (h *handler) writeComment(w io.Writer, comments ...string) {
@@ -363,11 +603,14 @@ func (h *handler) fetchRemoteSource(url string) (*bytes.Buffer, error) {
 		return nil, fmt.Errorf("wrong response code: %d", resp.StatusCode)
 	}
 
-	if ct, allowed := resp.Header.Get("Content-Type"), "text/plain"; !strings.HasPrefix(ct, allowed) {
-		return nil, fmt.Errorf("wrong Content-Type response header [%s] (%s* is required)", ct, allowed)
+	if ct := resp.Header.Get("Content-Type"); !isAllowedContentType(ct) {
+		return nil, fmt.Errorf("wrong Content-Type response header [%s] (text/plain* or compressed data is required)", ct)
 	}
 
-	var buf bytes.Buffer
+	var (
+		buf     bytes.Buffer
+		maxSize = int(h.cfg.RouterScript.MaxSourceSizeBytes)
+	)
 
 	const defaultBufCapacity = 64 * 1024 // 64 KiB
 
@@ -377,8 +620,8 @@ func (h *handler) fetchRemoteSource(url string) (*bytes.Buffer, error) {
 			return nil, errors.New("header Content-Length parsing error: " + parsingErr.Error())
 		}
 
-		if max := int(h.cfg.RouterScript.MaxSourceSizeBytes); value >= max {
-			return nil, fmt.Errorf("header Content-Length value [%d] is too big (max: %d)", value, max)
+		if value >= maxSize {
+			return nil, &sizeLimitError{what: fmt.Sprintf("header Content-Length value [%d]", value), max: maxSize}
 		}
 
 		if value > 0 {
@@ -390,26 +633,75 @@ func (h *handler) fetchRemoteSource(url string) (*bytes.Buffer, error) {
 		buf.Grow(defaultBufCapacity)
 	}
 
-	if _, readingErr := buf.ReadFrom(resp.Body); readingErr != nil {
+	// the Content-Length header can be missing (chunked transfer encoding) or wrong, so the limit is applied to the
+	// actually read bytes too (one extra byte is read to detect the overflow)
+	if _, readingErr := buf.ReadFrom(io.LimitReader(resp.Body, int64(maxSize)+1)); readingErr != nil {
 		return nil, readingErr
 	}
 
-	return &buf, nil
+	if buf.Len() >= maxSize {
+		return nil, &sizeLimitError{what: "response body size", max: maxSize}
+	}
+
+	content, err := decompress(buf.Bytes(), resp.Header, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewBuffer(content), nil
 }
 
+// validateSource scans the source content (records are discarded) and returns the scanning error (if any).
+func validateSource(content []byte, format hostsfile.Format) error {
+	var scanner = hostsfile.NewScanner(bytes.NewReader(content), format)
+
+	for scanner.Next() {
+		// records are discarded
+	}
+
+	return scanner.Err()
+}
+
+// sizeLimitError is returned when the source size exceeds the configured limit.
+type sizeLimitError struct {
+	what string // eg.: "response body size"
+	max  int
+}
+
+func (e *sizeLimitError) Error() string { return fmt.Sprintf("%s is too big (max: %d)", e.what, e.max) }
+
 type reqParams struct {
-	sources  []string
-	format   string
-	ver      string
-	excluded []string
-	limit    uint32
+	sources     []string
+	format      string
+	mode        string
+	update      string
+	since       string // previous generation hash (for the incremental script)
+	incremental bool   // store the script generation (implied by the previous generation hash)
+	idn         string
+	ver         string
+	excluded    []string
+	limit       uint32
+
+	chunk, chunkSize uint32 // entries chunk number (starting from 1) and size (zero means "without chunking")
+
+	list, timeout string // firewall address list name and entries timeout (for the address list format)
+
 	redirect net.IP
+
+	collapseWWW      bool // `www.example.com` and `example.com` are the same host
+	upstreamComments bool // include the upstream (inline) comments into the entries comments
+	matchSubdomain   bool // collapse subdomains into the parent domain entries with `match-subdomain=yes`
+	regexpCompaction bool // group hostnames with the same registrable domain into the `regexp=` entries
 }
 
 func newReqParams(redirect net.IP) reqParams {
 	return reqParams{
 		sources:  make([]string, 0, 8),
 		format:   formatRouterOS, // default value
+		mode:     modeRedirect,   // default value
+		update:   updateAppend,   // default value
+		idn:      idnPunycode,    // default value
+		list:     defaultAddressList,
 		excluded: make([]string, 0, 16),
 		redirect: redirect,
 	}
@@ -442,6 +734,28 @@ func (p *reqParams) fromValues(v url.Values) error { //nolint:funlen,gocognit,go
 		}
 	}
 
+	if value, ok := v["mode"]; ok { // optional
+		if len(value) > 0 {
+			switch value[0] {
+			case modeRedirect, modeNXDomain:
+				p.mode = value[0]
+			default:
+				return errors.New("wrong 'mode' value (allowed: " + modeRedirect + ", " + modeNXDomain + ")")
+			}
+		}
+	}
+
+	if value, ok := v["idn"]; ok { // optional
+		if len(value) > 0 {
+			switch value[0] {
+			case idnPunycode, idnUnicode:
+				p.idn = value[0]
+			default:
+				return errors.New("wrong 'idn' value (allowed: " + idnPunycode + ", " + idnUnicode + ")")
+			}
+		}
+	}
+
 	if value, ok := v["version"]; ok { // optional
 		if len(value) > 0 {
 			p.ver = value[0]
@@ -453,8 +767,14 @@ func (p *reqParams) fromValues(v url.Values) error { //nolint:funlen,gocognit,go
 
 		for i := range hosts {
 			for list, j := strings.Split(hosts[i], ","), 0; j < len(list); j++ {
-				if host := list[j]; host != "" {
-					m[strings.Trim(host, " '\"\n\r")] = struct{}{}
+				if host := strings.Trim(list[j], " '\"\n\r"); host != "" {
+					if !isASCII(host) { // internationalized domain names are compared in the ASCII form
+						if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+							host = ascii
+						}
+					}
+
+					m[host] = struct{}{}
 				}
 			}
 		}
@@ -476,6 +796,127 @@ func (p *reqParams) fromValues(v url.Values) error { //nolint:funlen,gocognit,go
 		}
 	}
 
+	if value, ok := v["list"]; ok { // optional
+		if len(value) > 0 {
+			if value[0] = strings.TrimSpace(value[0]); value[0] == "" || len(value[0]) > maxAddressListLength {
+				return errors.New("wrong 'list' value")
+			}
+
+			p.list = value[0]
+		}
+	}
+
+	if value, ok := v["timeout"]; ok { // optional
+		if len(value) > 0 {
+			if !isRouterOSTime(value[0]) {
+				return errors.New("wrong 'timeout' value (RouterOS time format, e.g. 1d12h or 01:30:00, is expected)")
+			}
+
+			p.timeout = value[0]
+		}
+	}
+
+	if value, ok := v["chunk"]; ok { // optional
+		if len(value) > 0 {
+			if number, err := strconv.ParseUint(value[0], 10, 32); err == nil && number > 0 {
+				p.chunk = uint32(number)
+			} else {
+				return errors.New("wrong 'chunk' value")
+			}
+		}
+	}
+
+	if value, ok := v["chunk_size"]; ok { // optional
+		if len(value) > 0 {
+			if size, err := strconv.ParseUint(value[0], 10, 32); err == nil && size > 0 {
+				p.chunkSize = uint32(size)
+			} else {
+				return errors.New("wrong 'chunk_size' value")
+			}
+
+			if p.chunk == 0 {
+				p.chunk = 1 // the first chunk by default
+			}
+		}
+	}
+
+	if value, ok := v["collapse_www"]; ok { // optional
+		if len(value) > 0 {
+			collapse, err := strconv.ParseBool(value[0])
+			if err != nil {
+				return errors.New("wrong 'collapse_www' value")
+			}
+
+			p.collapseWWW = collapse
+		}
+	}
+
+	if value, ok := v["match_subdomain"]; ok { // optional
+		if len(value) > 0 {
+			enabled, err := strconv.ParseBool(value[0])
+			if err != nil {
+				return errors.New("wrong 'match_subdomain' value")
+			}
+
+			p.matchSubdomain = enabled
+		}
+	}
+
+	if value, ok := v["update"]; ok { // optional
+		if len(value) > 0 {
+			switch value[0] {
+			case updateAppend, updateReplace, updateGuard:
+				p.update = value[0]
+			default:
+				return errors.New("wrong 'update' value (allowed: " + updateAppend + ", " + updateReplace + ", " +
+					updateGuard + ")")
+			}
+		}
+	}
+
+	if value, ok := v["incremental"]; ok { // optional
+		if len(value) > 0 {
+			enabled, err := strconv.ParseBool(value[0])
+			if err != nil {
+				return errors.New("wrong 'incremental' value")
+			}
+
+			p.incremental = enabled
+		}
+	}
+
+	if value, ok := v["since"]; ok { // optional
+		if len(value) > 0 {
+			if !isGenerationHash(value[0]) {
+				return errors.New("wrong 'since' value (generation hash is expected)")
+			}
+
+			p.since, p.incremental = strings.ToLower(value[0]), true
+		}
+	}
+
+	if value, ok := v["regexp_compaction"]; ok { // optional
+		if len(value) > 0 {
+			enabled, err := strconv.ParseBool(value[0])
+			if err != nil {
+				return errors.New("wrong 'regexp_compaction' value")
+			}
+
+			p.regexpCompaction = enabled
+		}
+	}
+
+	if value, ok := v["upstream_comments"]; ok { // optional
+		if len(value) > 0 {
+			enabled, err := strconv.ParseBool(value[0])
+			if err != nil {
+				return errors.New("wrong 'upstream_comments' value")
+			}
+
+			p.upstreamComments = enabled
+		}
+	}
+
 	if value, ok := v["redirect_to"]; ok { // optional
 		if len(value) > 0 {
 			ip := net.ParseIP(value[0])
@@ -490,6 +931,47 @@ func (p *reqParams) fromValues(v url.Values) error { //nolint:funlen,gocognit,go
 	return nil
 }
 
+// validateRouterOSOptions checks the options, that are supported by the RouterOS format only.
+func (p *reqParams) validateRouterOSOptions() error {
+	var option string
+
+	switch {
+	case p.matchSubdomain:
+		option = "subdomains matching"
+	case p.regexpCompaction:
+		option = "regexp compaction"
+	case p.update != updateAppend:
+		option = "update mode [" + p.update + "]"
+	case p.incremental:
+		option = "incremental script"
+	}
+
+	if option != "" && p.format != formatRouterOS {
+		return fmt.Errorf("%s is not supported by the [%s] format", option, p.format)
+	}
+
+	switch {
+	case p.regexpCompaction && p.matchSubdomain:
+		return errors.New("regexp compaction and subdomains matching cannot be used together")
+	case p.incremental && p.update == updateReplace:
+		return errors.New("incremental script cannot be used with the [" + updateReplace + "] update mode")
+	}
+
+	return nil
+}
+
+// removing returns the script feature, that removes the entries from the router (empty string if there is no one).
+func (p *reqParams) removing() string {
+	switch {
+	case p.update == updateReplace:
+		return "[" + updateReplace + "] update mode"
+	case p.since != "":
+		return "incremental script"
+	}
+
+	return ""
+}
+
 func (p *reqParams) validate(maxSources uint16) error {
 	if l := len(p.sources); l == 0 {
 		return errors.New("empty sources list")
@@ -501,5 +983,32 @@ func (p *reqParams) validate(maxSources uint16) error {
 		return errors.New("too many excluded hosts (more then 32)")
 	}
 
+	if p.chunkSize > 0 {
+		switch {
+		case p.incremental:
+			return errors.New("incremental script cannot be chunked")
+		case p.update == updateReplace:
+			return errors.New("update mode [" + updateReplace + "] cannot be used with the chunks")
+		}
+	} else if p.chunk > 0 {
+		return errors.New("chunk size is required for the chunk")
+	}
+
+	if err := p.validateRouterOSOptions(); err != nil {
+		return err
+	}
+
+	// resolvers match the queries against the ASCII (A-label) names, so the Unicode names are only presentational
+	if p.idn == idnUnicode && p.format != formatJSON && p.format != formatCSV {
+		return fmt.Errorf("[%s] names rendering is not supported by the [%s] format", idnUnicode, p.format)
+	}
+
+	if p.mode == modeNXDomain {
+		switch p.format {
+		case formatHosts, formatJSON, formatCSV, formatAddressList:
+			return fmt.Errorf("mode [%s] is not supported by the [%s] format", p.mode, p.format)
+		}
+	}
+
 	return nil
 }
@@ -3,6 +3,9 @@ package generate
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -26,8 +29,8 @@ type fakeHTTPClientFunc func(*http.Request) (*http.Response, error)
 func (f fakeHTTPClientFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
 
 type fakeMetrics struct {
-	h, m int
-	d    time.Duration
+	h, m, o int
+	d       time.Duration
 }
 
 const (
@@ -38,6 +41,7 @@ const (
 func (f *fakeMetrics) IncrementCacheHits()                       { f.h++ }
 func (f *fakeMetrics) IncrementCacheMisses()                     { f.m++ }
 func (f *fakeMetrics) ObserveGenerationDuration(d time.Duration) { f.d = d }
+func (f *fakeMetrics) IncrementOversizedSources()                { f.o++ }
 
 var httpMock fakeHTTPClientFunc = func(req *http.Request) (*http.Response, error) { //nolint:gochecknoglobals
 	path, absErr := filepath.Abs(testDataPath + req.URL.RequestURI())
@@ -70,6 +74,23 @@ var httpMock fakeHTTPClientFunc = func(req *http.Request) (*http.Response, error
 
 const testDataPath = "../../../../../test/testdata/hosts"
 
+// newTestHandler creates the handler with the in-memory cache (closed on the test cleanup) and mocked HTTP client.
+func newTestHandler(t *testing.T, cfg *config.Config, m metrics) *handler {
+	t.Helper()
+
+	cacher := cache.NewInMemoryCache(time.Minute, time.Second)
+	t.Cleanup(func() { _ = cacher.Close() })
+
+	h, err := NewHandler(context.Background(), zap.NewNop(), cacher, cfg, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.(*handler).httpClient = httpMock
+
+	return h.(*handler)
+}
+
 func createConfig() *config.Config {
 	cfg := &config.Config{}
 	cfg.RouterScript.MaxSourcesCount = 10
@@ -328,3 +349,1111 @@ func TestHandler_ServeHTTPRequestWrongFormat(t *testing.T) {
 	assert.Equal(t, 0, m.h)
 	assert.Equal(t, 0, m.m)
 }
+
+func TestHandler_ServeHTTPHostsFormat(t *testing.T) {
+	m := fakeMetrics{}
+
+	h := newTestHandler(t, createConfig(), &m)
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=hosts&redirect_to=0.0.0.0"+
+			"&sources_urls=http://mock/hosts_adaway.txt,http://mock/spy.txt"+
+			"&excluded_hosts=localhost", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, plainTextContentType, rr.Header().Get(contentTypeHeader))
+	assert.Contains(t, body, "## Format: hosts\n")
+	assert.Regexp(t, `(?m)^0\.0\.0\.0 ads\.mobclix\.com$`, body)
+	assert.NotRegexp(t, `(?m)^0\.0\.0\.0 localhost$`, body)
+	assert.NotContains(t, body, "/ip dns static")
+	assert.NotContains(t, body, "add address=")
+
+	// every non-comment and non-empty line must be a hosts file record
+	for _, line := range strings.Split(body, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		assert.Regexp(t, `^0\.0\.0\.0 \S+$`, line)
+	}
+
+	assert.Equal(t, 0, m.h)
+	assert.Equal(t, 2, m.m)
+}
+
+func TestHandler_ServeHTTPDnsmasqFormat(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=dnsmasq&mode=nxdomain"+
+			"&sources_urls=http://mock/hosts_adaway.txt", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, body, "## Format: dnsmasq\n")
+	assert.Contains(t, body, "## Mode: nxdomain\n")
+	assert.Regexp(t, `(?m)^local=/ads\.mobclix\.com/$`, body)
+	assert.NotContains(t, body, "address=/")
+}
+
+func TestHandler_ServeHTTPRouterOSNXDomainMode(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?mode=nxdomain"+
+			"&sources_urls=http://mock/hosts_adaway.txt", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, body, "## Mode: nxdomain\n")
+	assert.Regexp(t, `(?m)^add comment="foo" disabled=no name="ads\.mobclix\.com" type=NXDOMAIN$`, body)
+	assert.NotContains(t, body, "address=")
+}
+
+func TestHandler_ServeHTTPUpdateModes(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	for update, wantRegexp := range map[string]string{
+		"":        `(?m)^/ip dns static\nadd address=127\.0\.0\.1 comment="foo" `,
+		"append":  `(?m)^/ip dns static\nadd address=127\.0\.0\.1 comment="foo" `,
+		"replace": `(?m)^/ip dns static\nremove \[find comment="foo"\]\nadd address=127\.0\.0\.1 comment="foo" `,
+		"guard":   `(?m)^/ip dns static\n:if \(\[:len \[find name="[^"]+"\]\] = 0\) do=\{add address=127\.0\.0\.1 `,
+	} {
+		t.Run(update, func(t *testing.T) {
+			var query = "sources_urls=http://mock/hosts_adaway.txt"
+
+			if update != "" { // default mode otherwise
+				query += "&update=" + update
+			}
+
+			var (
+				req, _ = http.NewRequest(http.MethodGet, "http://testing?"+query, http.NoBody)
+				rr     = httptest.NewRecorder()
+			)
+
+			h.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Regexp(t, wantRegexp, rr.Body.String())
+		})
+	}
+
+	for query, wantRegexp := range map[string]string{
+		"sources_urls=http://foo&update=foobar":             `(?mU)## Query parameters error.*update`,
+		"sources_urls=http://foo&update=guard&format=hosts": `(?mU)## Query parameters validation.*guard.*hosts`,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "http://testing?"+query, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Regexp(t, wantRegexp, rr.Body.String())
+	}
+
+	cfg := createConfig()
+	cfg.RouterScript.Comment = "" // all the entries without comment will be removed
+
+	h = newTestHandler(t, cfg, &fakeMetrics{})
+
+	req, _ := http.NewRequest(http.MethodGet, "http://testing?update=replace&sources_urls=http://foo", http.NoBody)
+	rr := httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "requires the script comment")
+}
+
+func TestHandler_ServeHTTPIncrementalScript(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var generationRegexp = regexp.MustCompile(`(?m)^## Generation: ([0-9a-f]{16}) `)
+
+	var serve = func(query string) (string, string) {
+		req, _ := http.NewRequest(http.MethodGet, "http://testing?"+query, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		body := rr.Body.String()
+		match := generationRegexp.FindStringSubmatch(body)
+
+		if assert.Len(t, match, 2, body) {
+			return body, match[1]
+		}
+
+		return body, ""
+	}
+
+	_, first := serve("sources_urls=http://mock/categories.txt&incremental=true")
+
+	// generation is stored with its own lifetime (not the cache TTL)
+	found, _, ttl, _ := h.cacher.Get(generationCacheKey(first))
+	assert.True(t, found)
+	assert.InDelta(t, defaultGenerationsTTL.Seconds(), ttl.Seconds(), 1)
+
+	// new hosts are added only
+	body, second := serve("sources_urls=http://mock/categories.txt,http://mock/subdomains.txt&since=" + first)
+
+	assert.NotEqual(t, first, second)
+	assert.Contains(t, body, "## Incremental script since the generation "+first+"\n")
+	assert.Contains(t, body, `add address=127.0.0.1 comment="foo" disabled=no name="a.ads.example.com"`)
+	assert.NotContains(t, body, "remove ")
+	assert.NotContains(t, body, `name="ads.example.com"`) // exists in both sources
+
+	// vanished hosts are removed only
+	body, third := serve("sources_urls=http://mock/subdomains.txt&since=" + strings.ToUpper(second))
+
+	assert.Contains(t, body, "\n/ip dns static\n"+
+		`remove [find comment="foo" name="plain.example.com"]`+"\n"+
+		`remove [find comment="foo" name="tracker.example.com"]`+"\n\n\n")
+	assert.NotContains(t, body, "add ")
+
+	// nothing changed
+	body, fourth := serve("sources_urls=http://mock/subdomains.txt&since=" + third)
+
+	assert.Equal(t, third, fourth)
+	assert.Contains(t, body, "\n/ip dns static\n\n\n")
+
+	// unknown generation
+	body, _ = serve("sources_urls=http://mock/subdomains.txt&since=0123456789abcdef")
+
+	assert.Contains(t, body, "## Generation 0123456789abcdef was not found (expired?), full script (with the "+
+		"managed entries replacement) generated\n")
+	assert.Contains(t, body, "\n/ip dns static\n"+`remove [find comment="foo"]`+"\n"+"add ")
+	assert.Contains(t, body, `add address=127.0.0.1 comment="foo" disabled=no name="ads.example.com"`)
+
+	// expired (e.g. after the restart) generation with the upstream comments and guards
+	body, _ = serve("sources_urls=http://mock/subdomains.txt&upstream_comments=true&update=guard" +
+		"&since=fedcba9876543210")
+
+	assert.Contains(t, body, "## Generation fedcba9876543210 was not found (expired?)")
+	assert.Contains(t, body, "\n/ip dns static\n"+`remove [find where comment="foo" or comment~"^foo: "]`+"\n")
+	assert.NotContains(t, body, ":if ([:len [find")
+
+	// generations are not stored without the incremental mode
+	req, _ := http.NewRequest(http.MethodGet, "http://testing?sources_urls=http://mock/categories.txt", http.NoBody)
+	rr := httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "## Generation: ")
+
+	for query, wantRegexp := range map[string]string{
+		"sources_urls=http://foo&since=foo":                             `(?mU)## Query parameters error.*since`,
+		"sources_urls=http://foo&incremental=foo":                       `(?mU)## Query parameters error.*incremental`,
+		"sources_urls=http://foo&incremental=true&format=json":          `(?mU)## Query parameters validation.*json`,
+		"sources_urls=http://foo&since=0123456789abcdef&format=dnsmasq": `(?mU)## Query parameters validation.*dnsmasq`,
+		"sources_urls=http://foo&since=0123456789abcdef&update=replace": `(?mU)## Query parameters validation.*replace`,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "http://testing?"+query, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Regexp(t, wantRegexp, rr.Body.String())
+	}
+
+	cfg := createConfig()
+	cfg.RouterScript.Comment = "" // hand-made entries cannot be distinguished
+
+	h = newTestHandler(t, cfg, &fakeMetrics{})
+
+	req, _ = http.NewRequest(http.MethodGet, "http://testing?incremental=true&sources_urls=http://foo", http.NoBody)
+	rr = httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Incremental script requires the script comment")
+}
+
+func TestChunk(t *testing.T) {
+	var entries = newTestEntries("a.com", "b.com", "c.com", "d.com", "e.com")
+
+	for name, tt := range map[string]struct {
+		giveEntries []hostEntry
+		giveNumber  int
+		giveSize    int
+		wantEntries []hostEntry
+		wantTotal   int
+	}{
+		"first":          {giveEntries: entries, giveNumber: 1, giveSize: 2, wantEntries: entries[0:2], wantTotal: 3},
+		"second":         {giveEntries: entries, giveNumber: 2, giveSize: 2, wantEntries: entries[2:4], wantTotal: 3},
+		"last":           {giveEntries: entries, giveNumber: 3, giveSize: 2, wantEntries: entries[4:5], wantTotal: 3},
+		"out of range":   {giveEntries: entries, giveNumber: 4, giveSize: 2, wantEntries: entries[:0], wantTotal: 3},
+		"size is bigger": {giveEntries: entries, giveNumber: 1, giveSize: 10, wantEntries: entries, wantTotal: 1},
+		"exact size":     {giveEntries: entries, giveNumber: 1, giveSize: 5, wantEntries: entries, wantTotal: 1},
+		"empty":          {giveEntries: entries[:0], giveNumber: 1, giveSize: 2, wantEntries: entries[:0], wantTotal: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, total := chunk(tt.giveEntries, tt.giveNumber, tt.giveSize)
+
+			assert.Equal(t, tt.wantEntries, result)
+			assert.Equal(t, tt.wantTotal, total)
+		})
+	}
+}
+
+func TestHandler_ServeHTTPChunks(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var (
+		entryRegexp = regexp.MustCompile(`(?m)^add address=127\.0\.0\.1 comment="foo" disabled=no name="([^"]+)"$`)
+		names       []string
+	)
+
+	for number := 1; number <= 3; number++ {
+		var (
+			req, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("http://testing?chunk=%d&chunk_size=150"+
+				"&sources_urls=http://mock/hosts_adaway.txt", number), http.NoBody)
+			rr = httptest.NewRecorder()
+		)
+
+		h.ServeHTTP(rr, req)
+
+		body := rr.Body.String()
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, body, fmt.Sprintf("## Chunk: %d of 3 (chunk size: 150)\n", number))
+		assert.Equal(t, "3", rr.Header().Get(chunksTotalHeader))
+		assert.Contains(t, body, "## Records count: 410 (1 records ignored)\n")
+		assert.NotContains(t, body, "## Generation: ")
+
+		for _, match := range entryRegexp.FindAllStringSubmatch(body, -1) {
+			names = append(names, match[1])
+		}
+	}
+
+	assert.Len(t, names, 410)
+	assert.IsIncreasing(t, names) // chunks are not overlapped
+
+	// past the last chunk
+	req, _ := http.NewRequest(http.MethodGet, "http://testing?chunk=4&chunk_size=150"+
+		"&sources_urls=http://mock/hosts_adaway.txt", http.NoBody)
+	rr := httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, rr.Code)
+	assert.Equal(t, "3", rr.Header().Get(chunksTotalHeader))
+	assert.Contains(t, rr.Body.String(), "## Chunk 4 is out of range (chunks count: 3)\n")
+	assert.NotContains(t, rr.Body.String(), "add ")
+
+	// formats without comments
+	req, _ = http.NewRequest(http.MethodGet, "http://testing?format=json&chunk=1&chunk_size=150"+
+		"&sources_urls=http://mock/hosts_adaway.txt", http.NoBody)
+	rr = httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "3", rr.Header().Get(chunksTotalHeader))
+
+	for query, wantRegexp := range map[string]string{
+		"sources_urls=http://foo&chunk=0&chunk_size=10":                `(?mU)## Query parameters error.*'chunk'`,
+		"sources_urls=http://foo&chunk=1&chunk_size=-1":                `(?mU)## Query parameters error.*'chunk_size'`,
+		"sources_urls=http://foo&chunk=2":                              `(?mU)## Query parameters validation.*size`,
+		"sources_urls=http://foo&chunk_size=10&update=replace":         `(?mU)## Query parameters validation.*replace`,
+		"sources_urls=http://foo&chunk_size=10&since=0123456789abcdef": `(?mU)## Query parameters validation.*chunked`,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "http://testing?"+query, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Regexp(t, wantRegexp, rr.Body.String())
+	}
+}
+
+func TestIsRouterOSTime(t *testing.T) {
+	for give, want := range map[string]bool{
+		"1d":         true,
+		"1w2d3h4m5s": true,
+		"12h30m":     true,
+		"90s":        true,
+		"01:30:00":   true,
+		"100:00:00":  true,
+		"1d1w":       false,
+		"1h1h":       false,
+		"d":          false,
+		"10":         false,
+		"1x":         false,
+		"1:3:00":     false,
+		"01:30":      false,
+		"":           false,
+	} {
+		assert.Equal(t, want, isRouterOSTime(give), give)
+	}
+}
+
+func TestHandler_ServeHTTPAddressListFormat(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=address-list&list=ads&timeout=1d12h"+
+			"&sources_urls=http://mock/hosts_adaway.txt", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Contains(t, body, "\n/ip firewall address-list\n")
+	assert.Contains(t, body, "add address=\"a.admob.com\" comment=\"foo\" disabled=no list=\"ads\" timeout=1d12h\n")
+	assert.NotContains(t, body, "/ip dns static")
+
+	for query, wantRegexp := range map[string]string{
+		"sources_urls=http://foo&format=address-list&list=":                `(?mU)## Query parameters error.*'list'`,
+		"sources_urls=http://foo&format=address-list&timeout=1y":           `(?mU)## Query parameters error.*'timeout'`,
+		"sources_urls=http://foo&format=address-list&mode=nxdomain":        `(?mU)## Query parameters validation.*nxdomain`,
+		"sources_urls=http://foo&format=address-list&match_subdomain=true": `(?mU)## Query parameters validation.*subdomains`,
+	} {
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?"+query, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Regexp(t, wantRegexp, rr.Body.String())
+	}
+}
+
+func TestHandler_ServeHTTPRequestWrongMode(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	for query, wantRegexp := range map[string]string{
+		"sources_urls=http://foo&mode=foobar":                `(?mU)## Query parameters error.*mode`,
+		"sources_urls=http://foo&format=hosts&mode=nxdomain": `(?mU)## Query parameters validation.*nxdomain.*hosts`,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "http://testing?"+query, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Regexp(t, wantRegexp, rr.Body.String())
+	}
+}
+
+func TestHandler_ServeHTTPUnboundFormat(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=unbound&redirect_to=::1&limit=100"+
+			"&sources_urls=http://mock/hosts_adaway.txt&excluded_hosts=localhost", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, body, "\nserver:\n")
+	assert.NotContains(t, body, `local-zone: "localhost"`)
+	assert.Equal(t, 100, strings.Count(body, "\" redirect\n"))
+	assert.Equal(t, 100, strings.Count(body, " AAAA ::1\"\n"))
+}
+
+func TestHandler_ServeHTTPRPZFormat(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=rpz&mode=nxdomain"+
+			"&sources_urls=http://mock/hosts_adaway.txt,http://non-existing-file.txt", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/dns", rr.Header().Get(contentTypeHeader))
+	assert.NotRegexp(t, `(?m)^#`, body) // hash comments are not allowed in zone files
+	assert.Contains(t, body, ";; Format: rpz\n")
+	assert.Regexp(t, `;; Source.+non-existing-file\.txt.+404`, body)
+	assert.Regexp(t, `(?m)^ads\.mobclix\.com CNAME \.$`, body)
+
+	var serialRegex = regexp.MustCompile(`(?m)^@ IN SOA \S+ \S+ \((\d+) `)
+
+	firstSerial := serialRegex.FindStringSubmatch(body)
+	assert.Len(t, firstSerial, 2)
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req) // the same hosts set must produce the same serial
+
+	assert.Equal(t, firstSerial, serialRegex.FindStringSubmatch(rr.Body.String()))
+
+	req, _ = http.NewRequest(http.MethodGet, "http://testing?format=rpz&mode=nxdomain"+
+		"&sources_urls=http://mock/hosts_adaway.txt&excluded_hosts=ads.mobclix.com", http.NoBody)
+	rr = httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req) // changed hosts set must produce the greater serial
+
+	secondSerial := serialRegex.FindStringSubmatch(rr.Body.String())
+	if assert.Len(t, secondSerial, 2) {
+		first, _ := strconv.ParseUint(firstSerial[1], 10, 32)
+		second, _ := strconv.ParseUint(secondSerial[1], 10, 32)
+
+		assert.Greater(t, second, first)
+	}
+}
+
+func TestHandler_ZoneSerial(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var first, second = h.zoneSerial("aaaa"), h.zoneSerial("bbbb") // generated during the same second
+
+	assert.InDelta(t, time.Now().Unix(), first, 2)
+	assert.Greater(t, second, first)
+	assert.Equal(t, first, h.zoneSerial("aaaa"))
+	assert.Equal(t, second, h.zoneSerial("bbbb"))
+
+	// serials outlive the cached sources
+	cacher := cache.NewInMemoryCache(time.Millisecond*10, time.Millisecond)
+	t.Cleanup(func() { _ = cacher.Close() })
+
+	h.cacher = cacher
+
+	first = h.zoneSerial("aaaa")
+
+	<-time.After(time.Millisecond * 30) // source cache TTL is expired
+
+	found, _, _, _ := cacher.Get(zoneSerialCacheKey("aaaa")) //nolint:dogsled
+	assert.True(t, found)
+	assert.Equal(t, first, h.zoneSerial("aaaa"))
+}
+
+func TestHandler_ServeHTTPJSONFormat(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=json&redirect_to=0.0.0.0"+
+			"&sources_urls=http://mock/hosts_adaway.txt,http://mock/hosts_someonewhocares.txt"+
+			",http://non-existing-file.txt", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get(contentTypeHeader))
+
+	var entries []struct {
+		Name    string   `json:"name"`
+		Address string   `json:"address"`
+		Sources []string `json:"sources"`
+	}
+
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	assert.NotEmpty(t, entries)
+
+	var both, found = 0, false
+
+	for _, entry := range entries {
+		assert.Equal(t, "0.0.0.0", entry.Address)
+		assert.NotEmpty(t, entry.Sources)
+
+		if len(entry.Sources) == 2 {
+			both++
+		}
+
+		if entry.Name == "ads.mobclix.com" {
+			found = true
+
+			assert.Contains(t, entry.Sources, "http://mock/hosts_adaway.txt")
+		}
+	}
+
+	assert.True(t, found)
+	assert.Positive(t, both) // some hosts are presented in both sources
+}
+
+func TestHandler_ServeHTTPCSVFormat(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=csv&limit=10"+
+			"&sources_urls=http://mock/hosts_adaway.txt", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	records, csvErr := csv.NewReader(rr.Body).ReadAll()
+	assert.NoError(t, csvErr)
+
+	assert.Len(t, records, 10+1) // + header
+	assert.Equal(t, []string{"name", "address", "sources"}, records[0])
+
+	for _, record := range records[1:] {
+		assert.Equal(t, "127.0.0.1", record[1])
+		assert.Equal(t, "http://mock/hosts_adaway.txt", record[2])
+	}
+}
+
+func TestHandler_ServeHTTPAdBlockSource(t *testing.T) {
+	cfg := createConfig()
+	cfg.AddSource("http://mock/adblock.txt", "AdBlock", "", true, 0)
+	cfg.Sources[0].Format = "adblock"
+
+	h := newTestHandler(t, cfg, &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=hosts"+
+			"&sources_urls=http://mock/adblock.txt,http://mock/spy.txt", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	for range 2 { // the second run uses cached content
+		rr = httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		body := rr.Body.String()
+
+		assert.Regexp(t, `(?m)^127\.0\.0\.1 ads\.example\.com$`, body)
+		assert.Regexp(t, `(?m)^127\.0\.0\.1 metrics\.example\.net$`, body)
+		assert.NotContains(t, body, "good.example.com")                  // exception
+		assert.NotContains(t, body, "wildcard.example.com")              // unsupported rule
+		assert.Regexp(t, `(?m)^127\.0\.0\.1 \S+\.microsoft\.com$`, body) // hosts file source
+	}
+}
+
+func TestHandler_ServeHTTPExceptionsAfterLimit(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	h.httpClient = fakeHTTPClientFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{contentTypeHeader: []string{plainTextContentType}},
+			Body:       io.NopCloser(strings.NewReader("||a.com^\n||b.com^\n||c.com^\n@@||a.com^\n")),
+		}, nil
+	})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=hosts&limit=2"+
+			"&sources_urls=http://mock/adblock-with-exception.txt", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, body, " a.com\n") // excepted after the limit was reached
+	assert.Contains(t, body, "127.0.0.1 b.com\n")
+	assert.Contains(t, body, "127.0.0.1 c.com\n") // takes the excepted host place
+	assert.Contains(t, body, "## Records count: 2 ")
+}
+
+func TestHandler_ServeHTTPPartiallyParsedSource(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	h.httpClient = fakeHTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+		var content = "0.0.0.0 good.com\n"
+
+		if req.URL.Path == "/broken.txt" { // the scanner fails on the too long line
+			content = "0.0.0.0 partial.com\n0.0.0.0 " + strings.Repeat("x", 128*1024) + "\n0.0.0.0 tail.com\n"
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{contentTypeHeader: []string{plainTextContentType}},
+			Body:       io.NopCloser(strings.NewReader(content)),
+		}, nil
+	})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=hosts"+
+			"&sources_urls=http://mock/good.txt,http://mock/broken.txt", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, body, "## Source <http://mock/broken.txt> error: ")
+	assert.Contains(t, body, "127.0.0.1 good.com\n")
+	assert.NotContains(t, body, "partial.com") // records of the failed source are not merged
+	assert.Contains(t, body, "## Records count: 1 (0 records ignored)\n")
+}
+
+func TestNewHandlerWrongSourceFormat(t *testing.T) {
+	cfg := createConfig()
+	cfg.AddSource("http://mock/adblock.txt", "AdBlock", "", true, 0)
+	cfg.Sources[0].Format = "foobar"
+
+	cacher := cache.NewInMemoryCache(time.Minute, time.Second)
+	t.Cleanup(func() { _ = cacher.Close() })
+
+	h, err := NewHandler(context.Background(), zap.NewNop(), cacher, cfg, &fakeMetrics{})
+
+	assert.Nil(t, h)
+	assert.ErrorContains(t, err, "foobar")
+}
+
+func TestHandler_ServeHTTPSourceFormatDetection(t *testing.T) {
+	cfg := createConfig()
+	cfg.AddSource("http://mock/hosts_adaway.txt", "AdAway", "", true, 0)
+	cfg.Sources[0].Format = "hosts"
+
+	h := newTestHandler(t, cfg, &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=hosts&sources_urls="+
+			"http://mock/hosts_adaway.txt,http://mock/adblock.txt,http://mock/domains.txt"+
+			",http://mock/dnsmasq.conf,http://mock/rpz.zone", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+
+	assert.Contains(t, body, "## Source <http://mock/hosts_adaway.txt> format: hosts, records: 411\n")
+	assert.Contains(t, body, "## Source <http://mock/adblock.txt> format: adblock (detected), records: 6\n")
+	assert.Contains(t, body, "## Source <http://mock/domains.txt> format: domains (detected), records: 4\n")
+	assert.Contains(t, body, "## Source <http://mock/dnsmasq.conf> format: dnsmasq (detected), records: 5\n")
+	assert.Contains(t, body, "## Source <http://mock/rpz.zone> format: rpz (detected), records: 6\n")
+
+	for _, host := range []string{"ads.mobclix.com", "tracker.example.org", "xn--e1aybc.xn--p1ai", "local.example.com"} {
+		assert.Regexp(t, `(?m)^127\.0\.0\.1 `+regexp.QuoteMeta(host)+`$`, body)
+	}
+}
+
+func TestHandler_ServeHTTPInternationalizedDomainNames(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	for query, wantHosts := range map[string][]string{
+		"": {"xn--e1afmkfd.xn--p1ai", "xn--bcher-kva.example", "ascii.example.com"},
+		"&idn=punycode&excluded_hosts=Bücher.example": {"xn--e1afmkfd.xn--p1ai", "ascii.example.com"},
+	} {
+		t.Run(query, func(t *testing.T) {
+			var (
+				req, _ = http.NewRequest(http.MethodGet, "http://testing?format=hosts"+
+					"&sources_urls=http://mock/idn.txt"+query, http.NoBody)
+				rr = httptest.NewRecorder()
+			)
+
+			h.ServeHTTP(rr, req)
+
+			body := rr.Body.String()
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, body, "## Records count: "+strconv.Itoa(len(wantHosts))+" ")
+
+			for _, host := range wantHosts {
+				assert.Regexp(t, `(?m)^127\.0\.0\.1 `+regexp.QuoteMeta(host)+`$`, body)
+			}
+		})
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://testing?format=csv&idn=unicode"+
+		"&sources_urls=http://mock/idn.txt", http.NoBody)
+	rr := httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	for _, host := range []string{"пример.рф", "bücher.example", "ascii.example.com"} {
+		assert.Regexp(t, `(?m)^`+regexp.QuoteMeta(host)+`,127\.0\.0\.1,`, rr.Body.String())
+	}
+
+	for query, wantRegexp := range map[string]string{
+		"sources_urls=http://foo&idn=foobar":               `(?mU)## Query parameters error.*idn`,
+		"sources_urls=http://foo&idn=unicode":              `(?mU)## Query parameters validation.*unicode.*routeros`,
+		"sources_urls=http://foo&idn=unicode&format=hosts": `(?mU)## Query parameters validation.*unicode.*hosts`,
+	} {
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?"+query, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Regexp(t, wantRegexp, rr.Body.String())
+	}
+}
+
+func TestHandler_ServeHTTPDuplicatesCollapsing(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	for query, tt := range map[string]struct {
+		wantHosts     []string
+		wantCollapsed int
+	}{
+		"":                {wantHosts: []string{"example.com", "www.example.com", "foo.example.org"}, wantCollapsed: 2},
+		"&collapse_www=0": {wantHosts: []string{"example.com", "www.example.com", "foo.example.org"}, wantCollapsed: 2},
+		"&collapse_www=1": {wantHosts: []string{"example.com", "foo.example.org"}, wantCollapsed: 3},
+	} {
+		t.Run(query, func(t *testing.T) {
+			var (
+				req, _ = http.NewRequest(http.MethodGet, "http://testing?format=hosts"+
+					"&sources_urls=http://mock/duplicates.txt"+query, http.NoBody)
+				rr = httptest.NewRecorder()
+			)
+
+			h.ServeHTTP(rr, req)
+
+			body := rr.Body.String()
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, body, "## Records count: "+strconv.Itoa(len(tt.wantHosts))+" ")
+			assert.Contains(t, body, "## Duplicates collapsed: "+strconv.Itoa(tt.wantCollapsed)+"\n")
+			assert.NotContains(t, body, "Example.COM")
+			assert.NotContains(t, body, "example.com.\n")
+
+			for _, host := range tt.wantHosts {
+				assert.Regexp(t, `(?m)^127\.0\.0\.1 `+regexp.QuoteMeta(host)+`$`, body)
+			}
+		})
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://testing?sources_urls=http://foo&collapse_www=foo", http.NoBody)
+	rr := httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Regexp(t, `(?mU)## Query parameters error.*collapse_www`, rr.Body.String())
+}
+
+func TestHandler_ServeHTTPUpstreamComments(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	for query, wantLines := range map[string][]string{
+		"": {
+			`add address=127.0.0.1 comment="foo" disabled=no name="ads.example.com"`,
+			`add address=127.0.0.1 comment="foo" disabled=no name="tracker.example.com"`,
+			`add address=127.0.0.1 comment="foo" disabled=no name="plain.example.com"`,
+		},
+		"&upstream_comments=true": {
+			`add address=127.0.0.1 comment="foo: [ads]" disabled=no name="ads.example.com"`,
+			`add address=127.0.0.1 comment="foo: [tracking] \"quoted\" \\ \$var" disabled=no name="tracker.example.com"`,
+			`add address=127.0.0.1 comment="foo" disabled=no name="plain.example.com"`,
+		},
+	} {
+		t.Run(query, func(t *testing.T) {
+			var (
+				req, _ = http.NewRequest(http.MethodGet, "http://testing?format=routeros"+ //nolint:misspell
+					"&sources_urls=http://mock/categories.txt"+query, http.NoBody)
+				rr = httptest.NewRecorder()
+			)
+
+			h.ServeHTTP(rr, req)
+
+			body := rr.Body.String()
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+
+			for _, line := range wantLines {
+				assert.Contains(t, body, line+"\n")
+			}
+		})
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://testing?sources_urls=http://foo&upstream_comments=foo", http.NoBody)
+	rr := httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Regexp(t, `(?mU)## Query parameters error.*upstream_comments`, rr.Body.String())
+}
+
+func TestHandler_ServeHTTPMatchSubdomain(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?match_subdomain=true"+
+			"&sources_urls=http://mock/subdomains.txt"+
+			"&excluded_hosts=cdn.tracker.example.org", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, body, "\n/ip dns static\n"+
+		`add address=127.0.0.1 comment="foo" disabled=no match-subdomain=yes name="ads.example.com"`+"\n"+
+		`add address=127.0.0.1 comment="foo" disabled=no match-subdomain=yes name="metrics.example.net"`+"\n"+
+		`add address=127.0.0.1 comment="foo" disabled=no match-subdomain=yes name="pixel.tracker.example.org"`+"\n"+
+		`add address=127.0.0.1 comment="foo" disabled=no name="tracker.example.org"`+"\n\n")
+	assert.Contains(t, body, "## Entries saved by the subdomains matching: 3\n")
+
+	for query, wantRegexp := range map[string]string{
+		"sources_urls=http://foo&match_subdomain=foo":              `(?mU)## Query parameters error.*match_subdomain`,
+		"sources_urls=http://foo&match_subdomain=1&format=dnsmasq": `(?mU)## Query parameters validation.*dnsmasq`,
+	} {
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?"+query, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Regexp(t, wantRegexp, rr.Body.String())
+	}
+}
+
+func TestHandler_ServeHTTPRegexpCompaction(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?regexp_compaction=true"+
+			"&sources_urls=http://mock/subdomains.txt", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, body, "\n/ip dns static\n"+
+		`add address=127.0.0.1 comment="foo" disabled=no `+
+		`regexp="^(a\\.ads|ads|b\\.ads|cdn\\.b\\.ads)\\.example\\.com\$"`+"\n"+
+		`add address=127.0.0.1 comment="foo" disabled=no name="metrics.example.net"`+"\n"+
+		`add address=127.0.0.1 comment="foo" disabled=no regexp="^(pixel\\.tracker|tracker)\\.example\\.org\$"`+"\n\n")
+	assert.Contains(t, body, "## Entries saved by the regexp compaction: 4\n")
+
+	for query, wantRegexp := range map[string]string{
+		"sources_urls=http://foo&regexp_compaction=foo":                 `(?mU)## Query parameters error.*regexp_compaction`,
+		"sources_urls=http://foo&regexp_compaction=1&format=hosts":      `(?mU)## Query parameters validation.*hosts`,
+		"sources_urls=http://foo&regexp_compaction=1&match_subdomain=1": `(?mU)## Query parameters validation.*together`,
+	} {
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?"+query, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		h.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Regexp(t, wantRegexp, rr.Body.String())
+	}
+}
+
+func TestHandler_ServeHTTPCompressedSources(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=hosts"+
+			"&sources_urls=http://mock/hosts_adaway.txt.gz,http://mock/spy.zip", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, body, "## Source <http://mock/hosts_adaway.txt.gz> format: hosts (detected), records: 411\n")
+	assert.Contains(t, body, "## Source <http://mock/spy.zip> format: hosts (detected), records: 367\n")
+	assert.Regexp(t, `(?m)^127\.0\.0\.1 ads\.mobclix\.com$`, body)
+	assert.Regexp(t, `(?m)^127\.0\.0\.1 \S+\.microsoft\.com$`, body)
+
+	// cache contains decompressed content
+	_, cached, _, _ := h.cacher.Get("http://mock/hosts_adaway.txt.gz")
+	assert.True(t, bytes.HasPrefix(cached, []byte("#")))
+}
+
+func TestHandler_ServeHTTPCompressedSourceTooBig(t *testing.T) {
+	cfg := createConfig()
+	cfg.RouterScript.MaxSourceSizeBytes = 8 * 1024 // compressed file is smaller, but decompressed is bigger
+
+	h := newTestHandler(t, cfg, &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=hosts"+
+			"&sources_urls=http://mock/hosts_adaway.txt.gz", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(),
+		"## Source <http://mock/hosts_adaway.txt.gz> error: decompressed content size is too big (max: 8192)\n")
+}
+
+func TestHandler_ServeHTTPEmptyHostsList(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	for name, tt := range map[string]struct {
+		giveQuery  string
+		wantStatus int
+		wantErrors int
+	}{
+		"all sources failed": {
+			giveQuery:  "format=json&sources_urls=http://non-existing-file.txt,http://mock/not-found.txt",
+			wantStatus: http.StatusBadGateway,
+			wantErrors: 2,
+		},
+		"replace update mode": {
+			giveQuery:  "update=replace&sources_urls=http://non-existing-file.txt",
+			wantStatus: http.StatusBadGateway,
+			wantErrors: 1,
+		},
+		"all hosts excluded": {
+			giveQuery: "format=csv&sources_urls=http://mock/categories.txt" +
+				"&excluded_hosts=ads.example.com,tracker.example.com,plain.example.com",
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var (
+				req, _ = http.NewRequest(http.MethodGet, "http://testing?"+tt.giveQuery, http.NoBody)
+				rr     = httptest.NewRecorder()
+			)
+
+			h.ServeHTTP(rr, req)
+
+			body := rr.Body.String()
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Contains(t, body, "## Script generation failed (empty hosts list)\n")
+			assert.NotContains(t, body, "remove ")
+			assert.NotContains(t, body, "/ip dns static")
+			assert.Len(t, rr.Header().Values(sourceErrorHeader), tt.wantErrors)
+		})
+	}
+
+	// formats with comments are rendered as before (with the errors in comments)
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?sources_urls=http://mock/not-found.txt", http.NoBody)
+		rr     = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "## Source <http://mock/not-found.txt> error: wrong response code: 404\n")
+	assert.NotContains(t, rr.Body.String(), "Script generation failed")
+	assert.Len(t, rr.Header().Values(sourceErrorHeader), 1)
+}
+
+func TestHandler_ServeHTTPRemovingWithFailedSource(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	for query, wantFeature := range map[string]string{
+		"update=replace":         "[replace] update mode",
+		"since=0123456789abcdef": "incremental script",
+	} {
+		var (
+			req, _ = http.NewRequest(http.MethodGet, "http://testing?"+query+
+				"&sources_urls=http://mock/hosts_adaway.txt,http://mock/not-found.txt", http.NoBody)
+			rr = httptest.NewRecorder()
+		)
+
+		h.ServeHTTP(rr, req)
+
+		body := rr.Body.String()
+
+		assert.Equal(t, http.StatusBadGateway, rr.Code)
+		assert.Contains(t, body, "all the sources must be available for the "+wantFeature)
+		assert.Contains(t, body, "## Source <http://mock/not-found.txt> error: wrong response code: 404\n")
+		assert.NotContains(t, body, "remove ")
+		assert.NotContains(t, body, "add ")
+	}
+}
+
+func TestHandler_ServeHTTPSourceErrorHeader(t *testing.T) {
+	h := newTestHandler(t, createConfig(), &fakeMetrics{})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=json"+
+			"&sources_urls=http://mock/hosts_adaway.txt,http://mock/not-found.txt", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []string{"<http://mock/not-found.txt>: wrong response code: 404"},
+		rr.Header().Values(sourceErrorHeader))
+}
+
+func TestHandler_ServeHTTPSourceTooBig(t *testing.T) {
+	cfg := createConfig()
+	cfg.RouterScript.MaxSourceSizeBytes = 1024
+
+	var m = &fakeMetrics{}
+
+	h := newTestHandler(t, cfg, m)
+
+	h.httpClient = fakeHTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+		var header = http.Header{contentTypeHeader: []string{plainTextContentType}}
+
+		switch req.URL.Path {
+		case "/small.txt": // without Content-Length header (chunked transfer encoding)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader("0.0.0.0 foo.com\n")),
+			}, nil
+
+		case "/wrong-length.txt": // Content-Length header value is less than the real body size
+			header.Set("Content-Length", "16")
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(strings.Repeat("0.0.0.0 bar.com\n", 1024))),
+		}, nil
+	})
+
+	var (
+		req, _ = http.NewRequest(http.MethodGet, "http://testing?format=hosts"+
+			"&sources_urls=http://mock/small.txt,http://mock/chunked.txt,http://mock/wrong-length.txt", http.NoBody)
+		rr = httptest.NewRecorder()
+	)
+
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, body, "## Source <http://mock/small.txt> format: hosts (detected), records: 1\n")
+	assert.Contains(t, body, "## Source <http://mock/chunked.txt> error: response body size is too big (max: 1024)\n")
+	assert.Contains(t, body, "## Source <http://mock/wrong-length.txt> error: response body size is too big (max: 1024)\n")
+	assert.Contains(t, body, "\n127.0.0.1 foo.com\n")
+	assert.NotContains(t, body, "bar.com")
+	assert.Equal(t, 2, m.o)
+
+	hit, _, _, _ := h.cacher.Get("http://mock/chunked.txt")
+	assert.False(t, hit) // oversized sources are not cached
+}
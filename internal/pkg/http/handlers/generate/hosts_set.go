@@ -0,0 +1,214 @@
+package generate
+
+import (
+	"math"
+	"sort"
+
+	"gh.tarampamp.am/mikrotik-hosts-parser/v4/pkg/hostsfile"
+)
+
+// hostEntry is a merged hosts list entry.
+type hostEntry struct {
+	name      string
+	sources   []string // URLs of the sources that contain the host (filled only if sources tracking is enabled)
+	important bool     // exception rules cannot remove the entry (except important exceptions)
+	comment   string   // the first upstream (inline) comment for the host (filled only if comments tracking is enabled)
+
+	matchSubdomain bool   // the entry covers all the subdomains (see hostsSet.collapseSubdomains)
+	regexp         string // regular expression for the grouped hostnames, name is empty (see compactRegexps)
+}
+
+// hostsSetOptions describes the hosts set behavior.
+type hostsSetOptions struct {
+	trackSources  bool // remember the source URLs for each entry
+	trackComments bool // remember the upstream (inline) comment for each entry
+	collapseWWW   bool // `www.example.com` and `example.com` are the same entry
+}
+
+// hostsSet is a set of unique hostnames, merged from the different sources. Hostnames are normalized (see
+// hostsfile.Normalize), so `Example.COM`, `example.com.` and `example.com` are the same set entry. The limit is
+// applied to the result (after the exceptions are resolved), so the first (in the insertion order) entries are used.
+type hostsSet struct {
+	entries    map[string]hostEntry
+	order      []string // entries keys in the insertion order
+	excludes   map[string]struct{}
+	exceptions map[string]bool // hostnames from the exception rules (value means "important exception")
+	limit      int
+	opts       hostsSetOptions
+
+	variants  map[string]struct{} // non-canonical hostnames (e.g. `Example.COM`), that were added into the set
+	pending   map[string]struct{} // entries, added using non-canonical hostnames only (canonical was not added yet)
+	collapsed int                 // hostname forms count, collapsed into the existing entries
+}
+
+// newHostsSet creates hosts set with the entries limit (zero means "without limit") and excluded hosts list.
+func newHostsSet(limit int, excluded []string, opts hostsSetOptions) *hostsSet {
+	var size = limit // used for the entries map pre-allocation
+
+	if limit <= 0 {
+		limit, size = math.MaxInt, 0
+	}
+
+	// burn excludes map for fastest checking
+	var excludes = make(map[string]struct{}, len(excluded))
+	for i := range excluded {
+		excludes[hostsfile.Normalize(excluded[i], opts.collapseWWW)] = struct{}{}
+	}
+
+	return &hostsSet{
+		entries:    make(map[string]hostEntry, size),
+		order:      make([]string, 0, size),
+		excludes:   excludes,
+		exceptions: make(map[string]bool),
+		limit:      limit,
+		opts:       opts,
+		variants:   make(map[string]struct{}),
+		pending:    make(map[string]struct{}),
+	}
+}
+
+// addRecord adds all record hostnames (found in the source with passed URL) into the set. Hostnames from the
+// exception records are remembered and will be removed from the set result.
+func (s *hostsSet) addRecord(rec *hostsfile.Record, source string) {
+	if rec.Exception {
+		s.except(rec.Host, rec.Important)
+
+		for i := range rec.AdditionalHosts {
+			s.except(rec.AdditionalHosts[i], rec.Important)
+		}
+
+		return
+	}
+
+	s.add(rec.Host, source, rec.Important)
+
+	for i := range rec.AdditionalHosts {
+		s.add(rec.AdditionalHosts[i], source, rec.Important)
+	}
+
+	if s.opts.trackComments && rec.Comment != "" {
+		s.comment(rec.Host, rec.Comment)
+
+		for i := range rec.AdditionalHosts {
+			s.comment(rec.AdditionalHosts[i], rec.Comment)
+		}
+	}
+}
+
+// comment sets the upstream comment for the existing entry (the first comment wins).
+func (s *hostsSet) comment(name, comment string) {
+	var key = hostsfile.Normalize(name, s.opts.collapseWWW)
+
+	if entry, ok := s.entries[key]; ok && entry.comment == "" {
+		entry.comment = comment
+		s.entries[key] = entry
+	}
+}
+
+// add the hostname (found in the source with passed URL) into the set. Excluded hostnames are ignored.
+func (s *hostsSet) add(name, source string, important bool) {
+	if name == "" {
+		return
+	}
+
+	var key = hostsfile.Normalize(name, s.opts.collapseWWW)
+
+	if _, ok := s.excludes[key]; ok { // is in excludes list?
+		return
+	}
+
+	entry, exists := s.entries[key]
+	changed := !exists
+
+	// the key is used for the de-duplication only, the entry name must be a hostname from the list (`www.` prefix
+	// collapsing must not replace `www.example.com` with the unlisted `example.com`); the canonical form is preferred
+	if !exists {
+		entry.name = s.displayName(name, key)
+		s.order = append(s.order, key)
+	} else if entry.name != key && s.displayName(name, key) == key {
+		entry.name, changed = key, true
+	}
+
+	s.countForm(name, key, exists)
+
+	if important && !entry.important {
+		entry.important, changed = true, true
+	}
+
+	// records from the same source are added one after another, so checking the last source is enough
+	if l := len(entry.sources); s.opts.trackSources && (l == 0 || entry.sources[l-1] != source) {
+		entry.sources, changed = append(entry.sources, source), true
+	}
+
+	if changed {
+		s.entries[key] = entry
+	}
+}
+
+// displayName returns the normalized hostname (without the `www.` prefix collapsing).
+func (s *hostsSet) displayName(name, key string) string {
+	if !s.opts.collapseWWW {
+		return key
+	}
+
+	return hostsfile.Normalize(name, false)
+}
+
+// countForm counts the hostname forms, collapsed into the existing set entries (each distinct hostname form of the
+// same entry, except the first one, is a collapsed duplicate).
+func (s *hostsSet) countForm(name, key string, exists bool) {
+	if name == key { // canonical form
+		if _, ok := s.pending[key]; ok && exists {
+			delete(s.pending, key)
+
+			s.collapsed++
+		}
+
+		return
+	}
+
+	if _, ok := s.variants[name]; ok { // this form was already counted
+		return
+	}
+
+	s.variants[name] = struct{}{}
+
+	if exists {
+		s.collapsed++
+	} else {
+		s.pending[key] = struct{}{}
+	}
+}
+
+// except remembers the hostname from the exception rule.
+func (s *hostsSet) except(name string, important bool) {
+	if name != "" {
+		name = hostsfile.Normalize(name, s.opts.collapseWWW)
+
+		s.exceptions[name] = s.exceptions[name] || important
+	}
+}
+
+// sorted returns the first (in the insertion order) set entries up to the limit (without excepted hostnames), sorted
+// by the hostname.
+func (s *hostsSet) sorted() []hostEntry {
+	var result = make([]hostEntry, 0, min(len(s.entries), s.limit))
+
+	for _, name := range s.order {
+		if len(result) >= s.limit { // hostnames limit has been reached
+			break
+		}
+
+		var entry = s.entries[name]
+
+		if important, excepted := s.exceptions[name]; excepted && (important || !entry.important) {
+			continue
+		}
+
+		result = append(result, entry)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+
+	return result
+}
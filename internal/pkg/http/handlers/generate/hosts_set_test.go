@@ -0,0 +1,132 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gh.tarampamp.am/mikrotik-hosts-parser/v4/pkg/hostsfile"
+)
+
+func TestHostsSet(t *testing.T) {
+	var set = newHostsSet(4, []string{"excluded.com"}, hostsSetOptions{})
+
+	set.add("b.com", "http://foo/1.txt", false)
+	set.add("a.com", "http://foo/1.txt", false)
+	set.add("a.com", "http://foo/2.txt", false) // duplicate
+	set.add("", "http://foo/1.txt", false)      // empty
+	set.add("excluded.com", "http://foo/1.txt", false)
+
+	assert.Len(t, set.sorted(), 2)
+	assert.Equal(t, []hostEntry{{name: "a.com"}, {name: "b.com"}}, set.sorted())
+
+	set.add("c.com", "http://foo/1.txt", false)
+	set.add("d.com", "http://foo/1.txt", false)
+	set.add("e.com", "http://foo/1.txt", false) // over the limit
+
+	assert.Equal(t, []hostEntry{{name: "a.com"}, {name: "b.com"}, {name: "c.com"}, {name: "d.com"}}, set.sorted())
+
+	// the limit is applied after the exceptions (the next added entries are used instead of the excepted ones)
+	set.except("a.com", false)
+
+	assert.Equal(t, []hostEntry{{name: "b.com"}, {name: "c.com"}, {name: "d.com"}, {name: "e.com"}}, set.sorted())
+}
+
+func TestHostsSet_TrackSources(t *testing.T) {
+	var set = newHostsSet(10, nil, hostsSetOptions{trackSources: true})
+
+	set.add("a.com", "http://foo/1.txt", false)
+	set.add("a.com", "http://foo/1.txt", false) // duplicate in the same source
+	set.add("b.com", "http://foo/1.txt", false)
+	set.add("a.com", "http://foo/2.txt", false)
+
+	assert.Equal(t, []hostEntry{
+		{name: "a.com", sources: []string{"http://foo/1.txt", "http://foo/2.txt"}},
+		{name: "b.com", sources: []string{"http://foo/1.txt"}},
+	}, set.sorted())
+}
+
+func TestHostsSet_Exceptions(t *testing.T) {
+	var set = newHostsSet(10, nil, hostsSetOptions{})
+
+	for _, rec := range []hostsfile.Record{
+		{Host: "a.com", AdditionalHosts: []string{"b.com"}},
+		{Host: "c.com", Important: true},
+		{Host: "d.com", Important: true},
+		{Host: "e.com"},
+		{Host: "b.com", Exception: true},                  // removes regular entry
+		{Host: "c.com", Exception: true},                  // cannot remove important entry
+		{Host: "d.com", Exception: true, Important: true}, // important exception removes important entry
+		{Host: "f.com", Exception: true},                  // not in the set
+	} {
+		set.addRecord(&rec, "http://foo/1.txt")
+	}
+
+	assert.Equal(t, []hostEntry{{name: "a.com"}, {name: "c.com", important: true}, {name: "e.com"}}, set.sorted())
+}
+
+func TestHostsSet_Normalization(t *testing.T) {
+	var set = newHostsSet(10, []string{"Excluded.com."}, hostsSetOptions{})
+
+	for _, name := range []string{
+		"Example.COM",
+		"example.com.",
+		"example.com",
+		"Example.COM", // the same form again
+		"www.example.com",
+		"EXCLUDED.com",
+		"foo.com.",
+		"foo.com.",
+	} {
+		set.add(name, "http://foo/1.txt", false)
+	}
+
+	set.except("WWW.Example.com", false)
+
+	assert.Equal(t, []hostEntry{{name: "example.com"}, {name: "foo.com"}}, set.sorted())
+	assert.Equal(t, 2, set.collapsed)
+}
+
+func TestHostsSet_CollapseWWW(t *testing.T) {
+	var set = newHostsSet(10, []string{"www.excluded.com"}, hostsSetOptions{collapseWWW: true})
+
+	for _, name := range []string{"www.example.com", "example.com", "WWW.example.com", "excluded.com", "www.com"} {
+		set.add(name, "http://foo/1.txt", false)
+	}
+
+	assert.Equal(t, []hostEntry{{name: "example.com"}, {name: "www.com"}}, set.sorted())
+	assert.Equal(t, 2, set.collapsed)
+
+	set = newHostsSet(10, nil, hostsSetOptions{collapseWWW: true})
+
+	for _, name := range []string{"WWW.Tracker.com", "www.tracker.com.", "www.ads.com", "ads.com", "www.ads.com"} {
+		set.add(name, "http://foo/1.txt", false)
+	}
+
+	// listed hostname is used (the canonical form is preferred)
+	assert.Equal(t, []hostEntry{{name: "ads.com"}, {name: "www.tracker.com"}}, set.sorted())
+}
+
+func TestHostsSet_TrackComments(t *testing.T) {
+	var set = newHostsSet(10, []string{"excluded.com"}, hostsSetOptions{trackComments: true})
+
+	for _, rec := range []hostsfile.Record{
+		{Host: "a.com", AdditionalHosts: []string{"b.com"}, Comment: "ads"},
+		{Host: "A.com", Comment: "tracking"}, // the first comment wins
+		{Host: "c.com"},
+		{Host: "c.com", Comment: "malware"},
+		{Host: "excluded.com", Comment: "foo"},
+	} {
+		set.addRecord(&rec, "http://foo/1.txt")
+	}
+
+	assert.Equal(t, []hostEntry{
+		{name: "a.com", comment: "ads"},
+		{name: "b.com", comment: "ads"},
+		{name: "c.com", comment: "malware"},
+	}, set.sorted())
+
+	set = newHostsSet(10, nil, hostsSetOptions{})
+	set.addRecord(&hostsfile.Record{Host: "a.com", Comment: "ads"}, "http://foo/1.txt")
+	assert.Equal(t, []hostEntry{{name: "a.com"}}, set.sorted()) // comments tracking is disabled
+}
@@ -0,0 +1,144 @@
+package generate
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// maxRegexpLength is the maximal regular expression length for the static DNS entry (RouterOS limits the value
+// length, so the conservative value is used).
+const maxRegexpLength = 255
+
+// regexpGroup is a set of hostnames, that share the same registrable domain (eTLD+1, e.g. `example.co.uk`).
+type regexpGroup struct {
+	domain string
+	self   bool     // the registrable domain itself is in the group
+	subs   []string // subdomain parts of the hostnames (e.g. `ads` for the `ads.example.com`)
+	first  int      // index of the first group entry (used for the result ordering)
+}
+
+// compactRegexps groups the entries by the registrable domain into the regular expression entries (for the RouterOS
+// versions without the subdomains matching support), e.g. `ads.example.com`, `cdn.example.com` and `example.com`
+// become `^((ads|cdn)\.)?example\.com$`. Regular expressions length is limited by the maxRegexpLength (the group
+// will be split into several expressions, if needed). Entries comments are not kept for the grouped entries. Saved
+// entries count is returned too.
+func compactRegexps(entries []hostEntry) ([]hostEntry, int) {
+	var (
+		groups = make([]regexpGroup, 0, len(entries))
+		index  = make(map[string]int, len(entries)) // registrable domain to the group index
+	)
+
+	for i := range entries {
+		var name = entries[i].name
+
+		domain, err := publicsuffix.EffectiveTLDPlusOne(name)
+		if err != nil {
+			domain = name // public suffix itself or invalid hostname, cannot be grouped
+		}
+
+		idx, ok := index[domain]
+		if !ok {
+			idx, index[domain] = len(groups), len(groups)
+			groups = append(groups, regexpGroup{domain: domain, first: i})
+		}
+
+		if name == domain {
+			groups[idx].self = true
+		} else {
+			groups[idx].subs = append(groups[idx].subs, name[:len(name)-len(domain)-1])
+		}
+	}
+
+	var result = make([]hostEntry, 0, len(groups))
+
+	for i := range groups {
+		if g := &groups[i]; len(g.subs) == 0 || (len(g.subs) == 1 && !g.self) { // nothing to group
+			result = append(result, entries[g.first])
+
+			continue
+		}
+
+		result = append(result, groups[i].entries()...)
+	}
+
+	return result, len(entries) - len(result)
+}
+
+// entries builds the group entries (regular expressions are split by the length limit).
+func (g *regexpGroup) entries() []hostEntry {
+	var (
+		result = make([]hostEntry, 0, 1)
+		domain = regexp.QuoteMeta(g.domain)
+		self   = g.self                         // the registrable domain is not included into the expression yet
+		chunk  = make([]string, 0, len(g.subs)) // subdomain parts of the current expression
+		length int                              // current expression length
+	)
+
+	var flush = func() {
+		var expr = subdomainsRegexp(chunk, domain, self)
+
+		switch {
+		case len(chunk) == 1 && !self: // nothing to group
+			result = append(result, hostEntry{name: chunk[0] + "." + g.domain})
+		case len(expr) > maxRegexpLength: // too long hostname (it is the only one in the chunk)
+			result = append(result, hostEntry{name: g.domain}, hostEntry{name: chunk[0] + "." + g.domain})
+		default:
+			result = append(result, hostEntry{regexp: expr})
+		}
+
+		chunk, self = chunk[:0], false
+	}
+
+	for _, sub := range g.subs {
+		var part = len(regexp.QuoteMeta(sub))
+
+		if len(chunk) > 0 && length+1+part > maxRegexpLength {
+			flush()
+		}
+
+		if len(chunk) == 0 {
+			length = len(subdomainsRegexp(nil, domain, self)) + part
+		} else {
+			length += 1 + part // with the `|` separator
+		}
+
+		chunk = append(chunk, sub)
+	}
+
+	flush()
+
+	return result
+}
+
+// subdomainsRegexp builds the regular expression, that matches the subdomains (and the domain itself, if needed):
+// `^(a|b\.c)\.example\.com$` or `^((a|b\.c)\.)?example\.com$`.
+func subdomainsRegexp(subs []string, domain string, self bool) string {
+	var b strings.Builder
+
+	b.WriteString("^(")
+
+	if self {
+		b.WriteByte('(')
+	}
+
+	for i, sub := range subs {
+		if i > 0 {
+			b.WriteByte('|')
+		}
+
+		b.WriteString(regexp.QuoteMeta(sub))
+	}
+
+	if self {
+		b.WriteString(`)\.)?`)
+	} else {
+		b.WriteString(`)\.`)
+	}
+
+	b.WriteString(domain)
+	b.WriteByte('$')
+
+	return b.String()
+}
@@ -0,0 +1,170 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gh.tarampamp.am/mikrotik-hosts-parser/v4/pkg/hostsfile"
+)
+
+func TestCompactRegexps(t *testing.T) {
+	for name, tt := range map[string]struct {
+		giveHosts []string
+		wantItems []hostEntry
+		wantSaved int
+	}{
+		"empty": {
+			giveHosts: []string{},
+			wantItems: []hostEntry{},
+		},
+		"nothing to group": {
+			giveHosts: []string{"a.example.com", "example.org", "foo.co.uk"},
+			wantItems: []hostEntry{{name: "a.example.com"}, {name: "example.org"}, {name: "foo.co.uk"}},
+		},
+		"subdomains": {
+			giveHosts: []string{"a.example.com", "b.c.example.com", "foo.com"},
+			wantItems: []hostEntry{{regexp: `^(a|b\.c)\.example\.com$`}, {name: "foo.com"}},
+			wantSaved: 1,
+		},
+		"with the registrable domain": {
+			giveHosts: []string{"ads.example.co.uk", "example.co.uk"},
+			wantItems: []hostEntry{{regexp: `^((ads)\.)?example\.co\.uk$`}},
+			wantSaved: 1,
+		},
+		"public suffix": {
+			giveHosts: []string{"co.uk", "com"},
+			wantItems: []hostEntry{{name: "co.uk"}, {name: "com"}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var entries = make([]hostEntry, len(tt.giveHosts))
+
+			for i := range tt.giveHosts {
+				entries[i].name = tt.giveHosts[i]
+			}
+
+			result, saved := compactRegexps(entries)
+
+			assert.Equal(t, tt.wantItems, result)
+			assert.Equal(t, tt.wantSaved, saved)
+		})
+	}
+}
+
+func TestCompactRegexpsLengthLimit(t *testing.T) {
+	var hosts = []string{"example.com"}
+
+	for i := range 200 {
+		hosts = append(hosts, fmt.Sprintf("sub-domain-%03d.example.com", i))
+	}
+
+	hosts = append(hosts, fmt.Sprintf("%0250d.example.com", 0)) // too long for the regular expression
+
+	result := assertRegexpsMatchHosts(t, hosts)
+
+	assert.Greater(t, len(result), 1)
+	assert.Less(t, len(result), len(hosts)/10)
+	assert.Contains(t, result, hostEntry{name: fmt.Sprintf("%0250d.example.com", 0)})
+}
+
+func TestCompactRegexpsUsingHostsFileContent(t *testing.T) {
+	file, err := os.Open("../../../../../test/testdata/hosts/hosts_adaway.txt")
+	assert.NoError(t, err)
+
+	defer func() { assert.NoError(t, file.Close()) }()
+
+	records, err := hostsfile.Parse(file)
+	assert.NoError(t, err)
+
+	var set = newHostsSet(0, nil, hostsSetOptions{})
+
+	for i := range records {
+		set.addRecord(&records[i], "")
+	}
+
+	var hosts = make([]string, 0, len(records))
+
+	for _, entry := range set.sorted() {
+		hosts = append(hosts, entry.name)
+	}
+
+	result := assertRegexpsMatchHosts(t, hosts)
+
+	assert.Less(t, len(result), len(hosts))
+}
+
+// assertRegexpsMatchHosts compacts the hosts and checks, that the result (names and regular expressions) matches
+// all the original hosts and does not match anything else.
+func assertRegexpsMatchHosts(t *testing.T, hosts []string) []hostEntry {
+	t.Helper()
+
+	var entries = make([]hostEntry, len(hosts))
+
+	for i := range hosts {
+		entries[i].name = hosts[i]
+	}
+
+	result, saved := compactRegexps(entries)
+
+	assert.Equal(t, len(hosts)-len(result), saved)
+
+	var (
+		names = make(map[string]struct{})
+		exprs = make([]*regexp.Regexp, 0, len(result))
+	)
+
+	for _, entry := range result {
+		if entry.regexp == "" {
+			names[entry.name] = struct{}{}
+
+			continue
+		}
+
+		assert.Empty(t, entry.name)
+		assert.LessOrEqual(t, len(entry.regexp), maxRegexpLength)
+
+		exprs = append(exprs, regexp.MustCompile(entry.regexp))
+	}
+
+	var matches = func(host string) int {
+		var count int
+
+		if _, ok := names[host]; ok {
+			count++
+		}
+
+		for _, expr := range exprs {
+			if expr.MatchString(host) {
+				count++
+			}
+		}
+
+		return count
+	}
+
+	var original = make(map[string]struct{}, len(hosts))
+
+	for _, host := range hosts {
+		original[host] = struct{}{}
+
+		assert.Equal(t, 1, matches(host), host) // each host is matched exactly once
+	}
+
+	for _, host := range hosts {
+		for _, other := range []string{
+			"x" + host, host + "x", "x." + host, "x-" + host, host[1:],
+			strings.Replace(host, ".", "x", 1), // dots must be escaped
+		} {
+			if _, ok := original[other]; !ok {
+				assert.Zero(t, matches(other), other)
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,126 @@
+package generate
+
+import "strings"
+
+// domainTrie is a tree of the domain name labels (from the top-level domain to the leftmost label).
+type domainTrie struct {
+	children map[string]*domainTrie
+	terminal bool // the node is a hostname from the list
+	allowed  bool // the node or any of its subdomains is allowed (excluded or excepted), so it cannot be a wildcard
+}
+
+// insert adds the hostname into the trie and returns its node.
+func (t *domainTrie) insert(name string) *domainTrie {
+	var node = t
+
+	for name != "" {
+		var label string
+
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			label, name = name[i+1:], name[:i]
+		} else {
+			label, name = name, ""
+		}
+
+		next, ok := node.children[label]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[string]*domainTrie)
+			}
+
+			next = &domainTrie{}
+			node.children[label] = next
+		}
+
+		node = next
+	}
+
+	return node
+}
+
+// allow marks the hostname (and all its parent domains) as a domain, that cannot be blocked with the subdomains.
+func (t *domainTrie) allow(name string) {
+	for node := t; ; {
+		node.allowed = true
+
+		if name == "" {
+			return
+		}
+
+		var label string
+
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			label, name = name[i+1:], name[:i]
+		} else {
+			label, name = name, ""
+		}
+
+		next, ok := node.children[label]
+		if !ok {
+			return
+		}
+
+		node = next
+	}
+}
+
+// covered checks whether the hostname is covered by any of its (blocked with the subdomains) parent domains.
+func (t *domainTrie) covered(name string) bool {
+	var node = t
+
+	for name != "" {
+		if node != t && node.terminal && !node.allowed {
+			return true
+		}
+
+		var label string
+
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			label, name = name[i+1:], name[:i]
+		} else {
+			label, name = name, ""
+		}
+
+		if node = node.children[label]; node == nil {
+			return false
+		}
+	}
+
+	return false
+}
+
+// collapseSubdomains removes the entries, that are covered by their parent domain entries (e.g. `a.ads.example.com`
+// is covered by `ads.example.com`), and marks the rest of the entries with the "match subdomain" flag. Domains with
+// allowed (excluded or excepted) subdomains are not used for the collapsing. Removed entries count is returned too.
+func (s *hostsSet) collapseSubdomains(entries []hostEntry) ([]hostEntry, int) {
+	var (
+		root  = &domainTrie{}
+		nodes = make([]*domainTrie, len(entries))
+	)
+
+	for i := range entries {
+		nodes[i] = root.insert(entries[i].name)
+		nodes[i].terminal = true
+	}
+
+	for name := range s.excludes {
+		root.allow(name)
+	}
+
+	for name := range s.exceptions {
+		root.allow(name)
+	}
+
+	var result = entries[:0]
+
+	for i := range entries {
+		if root.covered(entries[i].name) {
+			continue
+		}
+
+		entries[i].matchSubdomain = !nodes[i].allowed
+		result = append(result, entries[i])
+	}
+
+	return result, len(entries) - len(result)
+}
@@ -0,0 +1,65 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostsSet_CollapseSubdomains(t *testing.T) {
+	var set = newHostsSet(0, []string{"good.tracker.org"}, hostsSetOptions{})
+
+	for _, name := range []string{
+		"ads.example.com",
+		"a.ads.example.com",
+		"b.ads.example.com",
+		"x.y.ads.example.com",
+		"example.net",
+		"ads.example.net",
+		"tracker.org",
+		"bad.tracker.org",
+		"foo.bar.com",
+		"bar.com.foo",
+		"com",
+	} {
+		set.add(name, "", false)
+	}
+
+	set.except("ok.example.net", false)
+
+	result, saved := set.collapseSubdomains(set.sorted())
+
+	assert.Equal(t, []hostEntry{
+		{name: "ads.example.net", matchSubdomain: true},
+		{name: "bad.tracker.org", matchSubdomain: true},
+		{name: "bar.com.foo", matchSubdomain: true},
+		{name: "com", matchSubdomain: true}, // covers `ads.example.com` (with subdomains) and `foo.bar.com`
+		{name: "example.net"},               // has excepted subdomain
+		{name: "tracker.org"},               // has excluded subdomain
+	}, result)
+	assert.Equal(t, 5, saved)
+}
+
+func TestDomainTrie_Covered(t *testing.T) {
+	var root = &domainTrie{}
+
+	root.insert("example.com").terminal = true
+	root.insert("foo.bar.org").terminal = true
+
+	for name, want := range map[string]bool{
+		"example.com":       false, // the domain itself
+		"a.example.com":     true,
+		"a.b.example.com":   true,
+		"notexample.com":    false,
+		"com":               false,
+		"bar.org":           false,
+		"x.foo.bar.org":     true,
+		"foo.bar.org.other": false,
+	} {
+		assert.Equal(t, want, root.covered(name), name)
+	}
+
+	root.allow("good.example.com")
+
+	assert.False(t, root.covered("a.example.com"))
+}
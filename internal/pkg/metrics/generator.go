@@ -12,6 +12,7 @@ const generatorNamespace = "generator"
 type Generator struct {
 	cacheHit  prometheus.Counter
 	cacheMiss prometheus.Counter
+	oversized prometheus.Counter
 	duration  prometheus.Histogram
 }
 
@@ -30,6 +31,12 @@ func NewGenerator() Generator {
 			Name:      "misses",
 			Help:      "The count of cache misses during script generation.",
 		}),
+		oversized: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: generatorNamespace,
+			Subsystem: "sources",
+			Name:      "oversized",
+			Help:      "The count of sources, rejected because of the size limit exceeding.",
+		}),
 		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
 			Namespace: generatorNamespace,
 			Subsystem: "time",
@@ -45,12 +52,15 @@ func (g *Generator) IncrementCacheHits() { g.cacheHit.Inc() }
 // IncrementCacheMisses increments cache misses counter.
 func (g *Generator) IncrementCacheMisses() { g.cacheMiss.Inc() }
 
+// IncrementOversizedSources increments rejected (because of the size limit) sources counter.
+func (g *Generator) IncrementOversizedSources() { g.oversized.Inc() }
+
 // ObserveGenerationDuration adds a single observation to the script generation histogram.
 func (g *Generator) ObserveGenerationDuration(d time.Duration) { g.duration.Observe(d.Seconds()) }
 
 // Register metrics with registerer.
 func (g *Generator) Register(reg prometheus.Registerer) error {
-	for _, c := range [...]prometheus.Collector{g.cacheHit, g.cacheMiss, g.duration} {
+	for _, c := range [...]prometheus.Collector{g.cacheHit, g.cacheMiss, g.oversized, g.duration} {
 		if e := reg.Register(c); e != nil {
 			return e
 		}
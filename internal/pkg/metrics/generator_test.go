@@ -23,11 +23,12 @@ func TestGenerator_Register(t *testing.T) {
 	count, err := testutil.GatherAndCount(registry,
 		"generator_cache_hits",
 		"generator_cache_misses",
+		"generator_sources_oversized",
 		"generator_time_duration",
 	)
 	assert.NoError(t, err)
 
-	assert.Equal(t, 3, count)
+	assert.Equal(t, 4, count)
 }
 
 func TestGenerator_IncrementCacheHits(t *testing.T) {
@@ -48,6 +49,16 @@ func TestGenerator_IncrementCacheMisses(t *testing.T) {
 	assert.Equal(t, float64(1), metric.Counter.GetValue())
 }
 
+func TestGenerator_IncrementOversizedSources(t *testing.T) {
+	gen := metrics.NewGenerator()
+
+	gen.IncrementOversizedSources()
+	gen.IncrementOversizedSources()
+
+	metric := getMetric(&gen, "generator_sources_oversized")
+	assert.Equal(t, float64(2), metric.Counter.GetValue())
+}
+
 func TestGenerator_ObserveGenerationDuration(t *testing.T) {
 	gen := metrics.NewGenerator()
 
@@ -0,0 +1,73 @@
+package hostsfile
+
+import (
+	"bytes"
+	"io"
+)
+
+// ParseAdBlock parses the DNS-relevant subset of the AdBlock/AdGuard filtering rules syntax and returns slice of
+// records (with empty IP addresses). Result order are same as in source.
+//
+// Supported rules are:
+//
+//	||example.com^             - blocking rule
+//	@@||example.com^           - exception rule (Record.Exception is true)
+//	||example.com^$important   - important rule (Record.Important is true)
+//	! comment                  - comment (lines, started with `#` are comments too)
+//
+// Rules with any other modifiers, wildcards, regular expressions or cosmetic rules are ignored.
+func ParseAdBlock(in io.Reader) ([]Record, error) { return parseAll(NewScanner(in, FormatAdBlock)) }
+
+// parseAdBlockRule parses single rule line. False will be returned for unsupported, invalid or comment lines.
+//
+//nolint:wsl_v5 // compact parser control flow is easier to follow without extra blank lines
+func parseAdBlockRule(line []byte) (Record, bool) {
+	var rec Record
+
+	line = bytes.TrimSpace(line)
+
+	if len(line) == 0 || line[0] == '!' || line[0] == '#' || line[0] == '[' {
+		return Record{}, false // empty line, comment or header (like `[Adblock Plus 2.0]`)
+	}
+
+	if bytes.HasPrefix(line, []byte("@@")) {
+		rec.Exception, line = true, line[2:]
+	}
+
+	if !bytes.HasPrefix(line, []byte("||")) {
+		return Record{}, false // only domain anchored rules are supported
+	}
+
+	line = line[2:]
+
+	end := bytes.IndexByte(line, '^')
+	if end <= 0 {
+		return Record{}, false // separator is required
+	}
+
+	domain, rest := line[:end], line[end+1:]
+	rest = bytes.TrimPrefix(rest, []byte("|"))
+
+	if len(rest) > 0 { //nolint:nestif
+		if rest[0] != '$' {
+			return Record{}, false
+		}
+
+		for _, modifier := range bytes.Split(rest[1:], []byte(",")) {
+			if !bytes.EqualFold(bytes.TrimSpace(modifier), []byte("important")) {
+				return Record{}, false // rules with any other modifiers are not applied globally
+			}
+
+			rec.Important = true
+		}
+	}
+
+	host, ok := hostname(domain)
+	if !ok {
+		return Record{}, false
+	}
+
+	rec.Host = host
+
+	return rec, true
+}
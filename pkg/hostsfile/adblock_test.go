@@ -0,0 +1,68 @@
+package hostsfile
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAdBlock(t *testing.T) {
+	file, err := os.Open("../../test/testdata/hosts/adblock.txt")
+	assert.NoError(t, err)
+
+	records, parseErr := ParseAdBlock(file)
+	assert.NoError(t, file.Close())
+	assert.NoError(t, parseErr)
+
+	assert.Equal(t, []Record{
+		{Host: "ads.example.com", Line: 7},
+		{Host: "tracker.example.org", Line: 8},
+		{Host: "metrics.example.net", Important: true, Line: 9},
+		{Host: "WWW.Banner.Example.com", Line: 10},
+		{Host: "good.example.com", Exception: true, Line: 13},
+		{Host: "partner.example.org", Exception: true, Important: true, Line: 14},
+	}, records)
+}
+
+func TestParseAdBlockRule(t *testing.T) {
+	for _, tt := range []struct {
+		giveLine   string
+		wantRecord Record
+		wantOk     bool
+	}{
+		{giveLine: "||example.com^", wantRecord: Record{Host: "example.com"}, wantOk: true},
+		{giveLine: "  ||example.com^  ", wantRecord: Record{Host: "example.com"}, wantOk: true},
+		{giveLine: "||example.com^$important", wantRecord: Record{Host: "example.com", Important: true}, wantOk: true},
+		{giveLine: "||example.com^$IMPORTANT", wantRecord: Record{Host: "example.com", Important: true}, wantOk: true},
+		{giveLine: "@@||example.com^", wantRecord: Record{Host: "example.com", Exception: true}, wantOk: true},
+		{giveLine: "||xn--e1aybc.xn--p1ai^", wantRecord: Record{Host: "xn--e1aybc.xn--p1ai"}, wantOk: true},
+		{giveLine: "! comment"},
+		{giveLine: "# comment"},
+		{giveLine: ""},
+		{giveLine: "||^"},
+		{giveLine: "||example.com"},
+		{giveLine: "||example.com^foo"},
+		{giveLine: "||example.com^$dnstype=AAAA"},
+		{giveLine: "||example.com^$important,client=127.0.0.1"},
+		{giveLine: "||exa mple.com^"},
+		{giveLine: `||exa"mple.com^`},
+		{giveLine: "example.com"},
+		{giveLine: "0.0.0.0 example.com"},
+	} {
+		t.Run(tt.giveLine, func(t *testing.T) {
+			rec, ok := parseAdBlockRule([]byte(tt.giveLine))
+
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantRecord, rec)
+		})
+	}
+}
+
+func TestParseAdBlockEmptyInput(t *testing.T) {
+	records, err := ParseAdBlock(bytes.NewReader(nil))
+
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+}
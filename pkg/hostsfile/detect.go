@@ -0,0 +1,114 @@
+package hostsfile
+
+import (
+	"bytes"
+	"io"
+	"net"
+)
+
+const (
+	// detectSampleLines is the maximal count of non-comment lines, used for the format detection.
+	detectSampleLines = 50
+
+	// detectSampleSize is the maximal content sample size (in bytes), used for the format detection.
+	detectSampleSize = 64 * 1024
+)
+
+// DetectFormat samples the first non-comment lines of the content and classifies it as hosts file, plain domains
+// list, AdBlock-style rules, dnsmasq configuration or RPZ zone file. If the format cannot be detected, FormatHosts
+// will be returned.
+func DetectFormat(sample []byte) Format {
+	var (
+		votes = make(map[Format]int, 5)
+		lines int
+	)
+
+	for len(sample) > 0 && lines < detectSampleLines {
+		var line []byte
+
+		if i := bytes.IndexByte(sample, '\n'); i >= 0 {
+			line, sample = sample[:i], sample[i+1:]
+		} else {
+			line, sample = sample, nil
+		}
+
+		if f, ok := detectLineFormat(line); ok {
+			votes[f]++
+			lines++
+		}
+	}
+
+	var result, max = FormatHosts, 0
+
+	// the order defines the priority (for the votes equality)
+	for _, f := range [...]Format{FormatHosts, FormatAdBlock, FormatDnsmasq, FormatRPZ, FormatDomains} {
+		if votes[f] > max {
+			result, max = f, votes[f]
+		}
+	}
+
+	return result
+}
+
+// detectLineFormat classifies single line. False will be returned for empty, comment or unknown lines.
+//
+//nolint:gocyclo // flat classification rules are easier to follow in one place
+func detectLineFormat(line []byte) (Format, bool) {
+	line = bytes.TrimSpace(line)
+
+	if len(line) == 0 {
+		return "", false
+	}
+
+	switch line[0] {
+	case '#', ';':
+		return "", false // comments
+	case '!', '[':
+		return FormatAdBlock, true // AdBlock-style comments and header (like `[Adblock Plus 2.0]`)
+	case '$':
+		return FormatRPZ, true // zone file directives (`$TTL`, `$ORIGIN`)
+	}
+
+	switch {
+	case bytes.HasPrefix(line, []byte("||")), bytes.HasPrefix(line, []byte("@@")):
+		return FormatAdBlock, true
+	case bytes.HasPrefix(line, []byte("address=/")),
+		bytes.HasPrefix(line, []byte("server=/")),
+		bytes.HasPrefix(line, []byte("local=/")):
+		return FormatDnsmasq, true
+	}
+
+	fields := bytes.Fields(line)
+
+	if len(fields) == 1 {
+		if _, ok := hostname(fields[0]); ok {
+			return FormatDomains, true
+		}
+
+		return "", false
+	}
+
+	for _, field := range fields[1:] {
+		if bytes.EqualFold(field, []byte("CNAME")) || bytes.EqualFold(field, []byte("SOA")) {
+			return FormatRPZ, true
+		}
+	}
+
+	if first := fields[0]; validateIPv4(first) || net.ParseIP(string(first)) != nil {
+		return FormatHosts, true
+	} else if _, ok := parseLongIP(string(first)); ok {
+		return FormatHosts, true
+	}
+
+	return "", false
+}
+
+// ParseAuto detects the input format (using the first part of the input) and parses it using the matching parser.
+// Detected format will be returned too.
+func ParseAuto(in io.Reader) ([]Record, Format, error) {
+	var s = NewScanner(in, FormatAuto)
+
+	records, err := parseAll(s)
+
+	return records, s.Format(), err
+}
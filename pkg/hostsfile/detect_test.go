@@ -0,0 +1,52 @@
+package hostsfile
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectFormat(t *testing.T) {
+	for _, tt := range []struct {
+		giveFilePath string
+		wantFormat   Format
+	}{
+		{giveFilePath: "../../test/testdata/hosts/ad_servers.txt", wantFormat: FormatHosts},
+		{giveFilePath: "../../test/testdata/hosts/hosts_adaway.txt", wantFormat: FormatHosts},
+		{giveFilePath: "../../test/testdata/hosts/hosts_someonewhocares.txt", wantFormat: FormatHosts},
+		{giveFilePath: "../../test/testdata/hosts/spy.txt", wantFormat: FormatHosts},
+		{giveFilePath: "../../test/testdata/hosts/adblock.txt", wantFormat: FormatAdBlock},
+		{giveFilePath: "../../test/testdata/hosts/domains.txt", wantFormat: FormatDomains},
+		{giveFilePath: "../../test/testdata/hosts/dnsmasq.conf", wantFormat: FormatDnsmasq},
+		{giveFilePath: "../../test/testdata/hosts/rpz.zone", wantFormat: FormatRPZ},
+	} {
+		t.Run(tt.giveFilePath, func(t *testing.T) {
+			content, err := os.ReadFile(tt.giveFilePath)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.wantFormat, DetectFormat(content))
+		})
+	}
+}
+
+func TestDetectFormatUnknownContent(t *testing.T) {
+	assert.Equal(t, FormatHosts, DetectFormat(nil))
+	assert.Equal(t, FormatHosts, DetectFormat([]byte("# only comments\n\n# here\n")))
+	assert.Equal(t, FormatHosts, DetectFormat([]byte("<html>foo bar</html>\n")))
+}
+
+func TestParseAuto(t *testing.T) {
+	records, f, err := ParseAuto(strings.NewReader("! comment\n||foo.com^\n||bar.com^\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, FormatAdBlock, f)
+	assert.Equal(t, []Record{{Host: "foo.com", Line: 2}, {Host: "bar.com", Line: 3}}, records)
+
+	records, f, err = ParseAuto(strings.NewReader("# comment\nfoo.com\nbar.com\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, FormatDomains, f)
+	assert.Equal(t, []Record{{Host: "foo.com", Line: 2}, {Host: "bar.com", Line: 3}}, records)
+}
@@ -0,0 +1,120 @@
+package hostsfile
+
+import (
+	"bytes"
+	"net"
+)
+
+// SkipReason describes why the hosts list line was skipped (does not contain any record).
+type SkipReason string
+
+// Reasons of the lines skipping.
+const (
+	SkipEmpty           SkipReason = "empty line"
+	SkipComment         SkipReason = "comment"
+	SkipTooShort        SkipReason = "too short"
+	SkipInvalidIP       SkipReason = "invalid IP"
+	SkipInvalidHostname SkipReason = "invalid hostname"
+	SkipUnsupported     SkipReason = "unsupported syntax"
+)
+
+// SkippedLine is the hosts list line without records.
+type SkippedLine struct {
+	Number  int // line number (starting from 1)
+	Reason  SkipReason
+	Content string
+}
+
+// Diagnostics contains information about the scanned hosts list lines. Lines without records are collected with the
+// skipping reasons, so it becomes clear why the source yields fewer records than expected.
+type Diagnostics struct {
+	Lines   int // total lines count
+	Records int // records count
+	Skipped []SkippedLine
+	Counts  map[SkipReason]int // skipped lines count per reason
+}
+
+func newDiagnostics() *Diagnostics {
+	return &Diagnostics{Counts: make(map[SkipReason]int)}
+}
+
+// skip remembers the skipped line.
+func (d *Diagnostics) skip(number int, reason SkipReason, line []byte) {
+	d.Skipped = append(d.Skipped, SkippedLine{Number: number, Reason: reason, Content: string(line)})
+	d.Counts[reason]++
+}
+
+// skipReason explains why the line (in passed format) without records was skipped.
+func skipReason(f Format, line []byte) SkipReason {
+	var trimmed = bytes.TrimSpace(line)
+
+	if len(trimmed) == 0 {
+		return SkipEmpty
+	}
+
+	switch f {
+	case FormatHosts:
+		return hostsSkipReason(line, trimmed)
+
+	case FormatAdBlock:
+		if bytes.IndexByte([]byte("!#["), trimmed[0]) >= 0 {
+			return SkipComment
+		}
+
+	case FormatDomains:
+		if trimmed[0] == '#' || trimmed[0] == '!' {
+			return SkipComment
+		}
+
+		return SkipInvalidHostname
+
+	case FormatDnsmasq:
+		if trimmed[0] == '#' {
+			return SkipComment
+		}
+
+	case FormatRPZ:
+		if trimmed[0] == ';' {
+			return SkipComment
+		}
+
+	case FormatAuto:
+	}
+
+	return SkipUnsupported
+}
+
+// hostsSkipReason repeats the hosts file parser checks to explain why the line was skipped.
+func hostsSkipReason(line, trimmed []byte) SkipReason {
+	if trimmed[0] == '#' {
+		return SkipComment
+	}
+
+	if len(line) <= 5 {
+		return SkipTooShort
+	}
+
+	var (
+		fields = bytes.Fields(trimmed)
+		ip     = fields[0]
+	)
+
+	switch {
+	case bytes.IndexByte(ip, '.') >= 0:
+		if !validateIPv4(ip) {
+			return SkipInvalidIP
+		}
+
+	case bytes.IndexByte(ip, ':') >= 0:
+		if net.ParseIP(string(ip)) == nil {
+			return SkipInvalidIP
+		}
+
+	default:
+		if _, ok := parseLongIP(string(ip)); !ok {
+			return SkipInvalidIP
+		}
+	}
+
+	return SkipInvalidHostname // IP address is valid, but there is no valid hostnames
+}
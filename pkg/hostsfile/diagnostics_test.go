@@ -0,0 +1,105 @@
+package hostsfile
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScannerDiagnostics(t *testing.T) {
+	var s = NewScanner(strings.NewReader(`# comment
+1.1.1.1 foo.com
+
+  # comment with spaces
+1.1.1
+256.1.1.1 bar.com
+2.2.2.2 b@d.com
+foo:bar baz.com
+4294967296 too-big.long.integer.ip
+::1 localhost
+`), FormatHosts)
+
+	assert.Nil(t, s.Diagnostics())
+
+	s.EnableDiagnostics()
+
+	var lines []int
+
+	for s.Next() {
+		lines = append(lines, s.Line())
+	}
+
+	assert.NoError(t, s.Err())
+	assert.Equal(t, []int{2, 10}, lines)
+
+	var d = s.Diagnostics()
+
+	assert.Equal(t, 10, d.Lines)
+	assert.Equal(t, 2, d.Records)
+	assert.Equal(t, []SkippedLine{
+		{Number: 1, Reason: SkipComment, Content: "# comment"},
+		{Number: 3, Reason: SkipEmpty, Content: ""},
+		{Number: 4, Reason: SkipComment, Content: "  # comment with spaces"},
+		{Number: 5, Reason: SkipTooShort, Content: "1.1.1"},
+		{Number: 6, Reason: SkipInvalidIP, Content: "256.1.1.1 bar.com"},
+		{Number: 7, Reason: SkipInvalidHostname, Content: "2.2.2.2 b@d.com"},
+		{Number: 8, Reason: SkipInvalidIP, Content: "foo:bar baz.com"},
+		{Number: 9, Reason: SkipInvalidIP, Content: "4294967296 too-big.long.integer.ip"},
+	}, d.Skipped)
+	assert.Equal(t, map[SkipReason]int{
+		SkipComment:         2,
+		SkipEmpty:           1,
+		SkipTooShort:        1,
+		SkipInvalidIP:       3,
+		SkipInvalidHostname: 1,
+	}, d.Counts)
+}
+
+func TestSkipReason(t *testing.T) {
+	for name, tt := range map[string]struct {
+		giveFormat Format
+		giveLine   string
+		wantReason SkipReason
+	}{
+		"hosts empty":              {giveFormat: FormatHosts, giveLine: " \t ", wantReason: SkipEmpty},
+		"hosts ip only":            {giveFormat: FormatHosts, giveLine: "0.0.0.0 # foo.com", wantReason: SkipInvalidHostname},
+		"adblock comment":          {giveFormat: FormatAdBlock, giveLine: "! Title: foo", wantReason: SkipComment},
+		"adblock section":          {giveFormat: FormatAdBlock, giveLine: "[Adblock Plus 2.0]", wantReason: SkipComment},
+		"adblock cosmetic rule":    {giveFormat: FormatAdBlock, giveLine: "foo.com##.banner", wantReason: SkipUnsupported},
+		"domains comment":          {giveFormat: FormatDomains, giveLine: "# foo", wantReason: SkipComment},
+		"domains invalid hostname": {giveFormat: FormatDomains, giveLine: "foo bar", wantReason: SkipInvalidHostname},
+		"dnsmasq comment":          {giveFormat: FormatDnsmasq, giveLine: "# foo", wantReason: SkipComment},
+		"dnsmasq unsupported":      {giveFormat: FormatDnsmasq, giveLine: "cache-size=1000", wantReason: SkipUnsupported},
+		"rpz comment":              {giveFormat: FormatRPZ, giveLine: "; foo", wantReason: SkipComment},
+		"rpz directive":            {giveFormat: FormatRPZ, giveLine: "$TTL 300", wantReason: SkipUnsupported},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.wantReason, skipReason(tt.giveFormat, []byte(tt.giveLine)))
+		})
+	}
+}
+
+func TestScannerDiagnosticsUsingHostsFileContent(t *testing.T) {
+	file, err := os.Open("../../test/testdata/hosts/hosts_someonewhocares.txt")
+	assert.NoError(t, err)
+
+	defer func() { assert.NoError(t, file.Close()) }()
+
+	var s = NewScanner(file, FormatHosts)
+
+	s.EnableDiagnostics()
+
+	for s.Next() {
+		_ = s.Record()
+	}
+
+	assert.NoError(t, s.Err())
+
+	var d = s.Diagnostics()
+
+	assert.Equal(t, 14309, d.Records)
+	assert.Equal(t, d.Lines, d.Records+len(d.Skipped))
+	assert.Equal(t, len(d.Skipped), d.Counts[SkipComment]+d.Counts[SkipEmpty]+d.Counts[SkipTooShort])
+}
@@ -0,0 +1,70 @@
+package hostsfile
+
+import (
+	"bytes"
+	"io"
+	"net"
+)
+
+// ParseDnsmasq parses the dnsmasq configuration and returns slice of records. Result order are same as in source.
+//
+// Supported options are (IP address is optional):
+//
+//	address=/example.com/0.0.0.0
+//	address=/example.com/example.net/
+//	server=/example.com/
+//	local=/example.com/
+//
+// Any other options and `server` options with upstream servers are ignored. Lines, started with `#` are comments.
+func ParseDnsmasq(in io.Reader) ([]Record, error) { return parseAll(NewScanner(in, FormatDnsmasq)) }
+
+// parseDnsmasqLine parses single dnsmasq configuration line. False will be returned for unsupported, invalid or
+// comment lines.
+func parseDnsmasqLine(line []byte) (Record, bool) {
+	line = bytes.TrimSpace(line)
+
+	if len(line) == 0 || line[0] == '#' {
+		return Record{}, false
+	}
+
+	var value []byte
+
+	for _, option := range [...]string{"address=/", "server=/", "local=/"} { // options, used for the hosts blocking
+		if bytes.HasPrefix(line, []byte(option)) {
+			value = line[len(option):]
+
+			break
+		}
+	}
+
+	// value format is `domain/[domain/...]<target>`, where the target is the IP address (can be empty)
+	sep := bytes.LastIndexByte(value, '/')
+	if sep <= 0 {
+		return Record{}, false
+	}
+
+	var rec Record
+
+	if target := value[sep+1:]; len(target) > 0 {
+		if line[0] != 'a' || net.ParseIP(string(target)) == nil {
+			return Record{}, false // `server` and `local` with upstream (or invalid IP address) are not blocking rules
+		}
+
+		rec.IP = string(target)
+	}
+
+	for _, domain := range bytes.Split(value[:sep], []byte("/")) {
+		host, ok := hostname(domain)
+		if !ok {
+			continue // `#` (all domains) and invalid domains
+		}
+
+		if rec.Host == "" {
+			rec.Host = host
+		} else {
+			rec.AdditionalHosts = append(rec.AdditionalHosts, host)
+		}
+	}
+
+	return rec, rec.Host != ""
+}
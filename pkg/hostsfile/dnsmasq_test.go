@@ -0,0 +1,25 @@
+package hostsfile
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDnsmasq(t *testing.T) {
+	file, err := os.Open("../../test/testdata/hosts/dnsmasq.conf")
+	assert.NoError(t, err)
+
+	records, parseErr := ParseDnsmasq(file)
+	assert.NoError(t, file.Close())
+	assert.NoError(t, parseErr)
+
+	assert.Equal(t, []Record{
+		{IP: "0.0.0.0", Host: "ads.example.com", Line: 2},
+		{IP: "::", Host: "tracker.example.org", AdditionalHosts: []string{"metrics.example.net"}, Line: 3},
+		{Host: "nxdomain.example.com", Line: 4},
+		{Host: "server.example.com", Line: 5},
+		{Host: "local.example.com", Line: 6},
+	}, records)
+}
@@ -0,0 +1,38 @@
+package hostsfile
+
+import (
+	"bytes"
+	"io"
+)
+
+// ParseDomains parses the plain domains list (one hostname per line, without IP address) and returns slice of records
+// (with empty IP addresses). Result order are same as in source.
+//
+// Lines, started with `#` or `!` are comments. Inline comments (`example.com # comment`) are allowed too.
+func ParseDomains(in io.Reader) ([]Record, error) { return parseAll(NewScanner(in, FormatDomains)) }
+
+// parseDomainLine parses single domains list line. False will be returned for invalid or comment lines.
+func parseDomainLine(line []byte) (Record, bool) {
+	var comment []byte
+
+	if i := bytes.IndexByte(line, '#'); i >= 0 {
+		line, comment = line[:i], line[i:] // cut the comment
+	}
+
+	line = bytes.TrimSpace(line)
+
+	if len(line) == 0 || line[0] == '!' {
+		return Record{}, false
+	}
+
+	if bytes.ContainsAny(line, " \t") {
+		return Record{}, false // only one hostname per line is allowed
+	}
+
+	host, ok := hostname(line)
+	if !ok {
+		return Record{}, false
+	}
+
+	return Record{Host: host, Comment: inlineComment(comment)}, true
+}
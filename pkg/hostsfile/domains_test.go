@@ -0,0 +1,50 @@
+package hostsfile
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDomains(t *testing.T) {
+	file, err := os.Open("../../test/testdata/hosts/domains.txt")
+	assert.NoError(t, err)
+
+	records, parseErr := ParseDomains(file)
+	assert.NoError(t, file.Close())
+	assert.NoError(t, parseErr)
+
+	assert.Equal(t, []Record{
+		{Host: "ads.example.com", Line: 5},
+		{Host: "tracker.example.org", Comment: "inline comment", Line: 6},
+		{Host: "metrics.example.net", Line: 7},
+		{Host: "xn--e1aybc.xn--p1ai", Line: 8},
+	}, records)
+}
+
+func TestParseDomainLine(t *testing.T) {
+	for _, tt := range []struct {
+		giveLine   string
+		wantRecord Record
+		wantOk     bool
+	}{
+		{giveLine: "example.com", wantRecord: Record{Host: "example.com"}, wantOk: true},
+		{giveLine: " example.com\t", wantRecord: Record{Host: "example.com"}, wantOk: true},
+		{giveLine: "example.com#comment", wantRecord: Record{Host: "example.com", Comment: "comment"}, wantOk: true},
+		{giveLine: "localhost", wantRecord: Record{Host: "localhost"}, wantOk: true},
+		{giveLine: ""},
+		{giveLine: "# comment"},
+		{giveLine: "! comment"},
+		{giveLine: "127.0.0.1 example.com"},
+		{giveLine: "foo.com bar.com"},
+		{giveLine: "exa$mple.com"},
+	} {
+		t.Run(tt.giveLine, func(t *testing.T) {
+			rec, ok := parseDomainLine([]byte(tt.giveLine))
+
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantRecord, rec)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package hostsfile
+
+import (
+	"io"
+	"strings"
+)
+
+// EncoderOptions describes hosts file encoding options.
+type EncoderOptions struct {
+	Header          []string // comment lines, that will be written before the records (eg.: `# Title: foo`)
+	DefaultIP       string   // IP address for the records without IP (records without IP are skipped if empty)
+	Align           bool     // align hostnames into the column (IP addresses are padded with spaces)
+	Group           bool     // group hostnames with the same IP address into one line (order of hostnames is kept)
+	MaxHostsPerLine int      // maximal hostnames count per line (zero means "without limit")
+	Comments        bool     // write records inline comments (ignored if grouping is enabled)
+}
+
+// Encoder writes records in the canonical hosts file format:
+//
+//	# header comment
+//
+//	0.0.0.0 example.com www.example.com # inline comment
+type Encoder struct {
+	w    io.Writer
+	opts EncoderOptions
+	buf  []byte
+}
+
+// NewEncoder creates a new hosts file encoder, that writes into passed writer.
+func NewEncoder(w io.Writer, opts ...EncoderOptions) *Encoder {
+	var e = &Encoder{w: w, buf: make([]byte, 0, 128)}
+
+	if len(opts) > 0 {
+		e.opts = opts[0]
+	}
+
+	return e
+}
+
+// Write records into the writer in the canonical hosts file format.
+func Write(w io.Writer, records []Record, opts ...EncoderOptions) error {
+	return NewEncoder(w, opts...).Encode(records)
+}
+
+type encodedLine struct {
+	ip      string
+	hosts   []string
+	comment string
+}
+
+// Encode writes the header and records. Exception records and records without IP address (when the default IP is
+// not set) are skipped.
+func (e *Encoder) Encode(records []Record) error {
+	var lines, width = e.lines(records), 0
+
+	if e.opts.Align {
+		for i := range lines {
+			width = max(width, len(lines[i].ip))
+		}
+	}
+
+	if len(e.opts.Header) > 0 {
+		e.buf = e.buf[:0]
+
+		for _, line := range e.opts.Header {
+			if line = strings.TrimRight(line, " \t"); line == "" {
+				e.buf = append(e.buf, "#\n"...)
+			} else {
+				e.buf = append(e.buf, "# "+line+"\n"...)
+			}
+		}
+
+		if _, err := e.w.Write(append(e.buf, '\n')); err != nil {
+			return err
+		}
+	}
+
+	for i := range lines {
+		hosts, limit := lines[i].hosts, len(lines[i].hosts)
+
+		if e.opts.MaxHostsPerLine > 0 {
+			limit = e.opts.MaxHostsPerLine
+		}
+
+		for len(hosts) > 0 {
+			var chunk = hosts[:min(limit, len(hosts))]
+
+			hosts = hosts[len(chunk):]
+
+			e.buf = append(e.buf[:0], lines[i].ip...)
+
+			for j := len(lines[i].ip); j < width; j++ {
+				e.buf = append(e.buf, ' ')
+			}
+
+			for _, host := range chunk {
+				e.buf = append(e.buf, ' ')
+				e.buf = append(e.buf, host...)
+			}
+
+			if lines[i].comment != "" {
+				e.buf = append(e.buf, " # "+lines[i].comment...)
+			}
+
+			if _, err := e.w.Write(append(e.buf, '\n')); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// lines converts records into the hosts file lines (grouped by IP address, if grouping is enabled).
+func (e *Encoder) lines(records []Record) []encodedLine {
+	var (
+		lines  = make([]encodedLine, 0, len(records))
+		groups map[string]int // IP address to the line index
+	)
+
+	if e.opts.Group {
+		groups = make(map[string]int)
+	}
+
+	for i := range records {
+		var rec = &records[i]
+
+		if rec.Exception || rec.Host == "" {
+			continue
+		}
+
+		var ip = rec.IP
+		if ip == "" {
+			if ip = e.opts.DefaultIP; ip == "" {
+				continue
+			}
+		}
+
+		if e.opts.Group {
+			if idx, ok := groups[ip]; ok {
+				lines[idx].hosts = append(append(lines[idx].hosts, rec.Host), rec.AdditionalHosts...)
+
+				continue
+			}
+
+			groups[ip] = len(lines)
+		}
+
+		var line = encodedLine{ip: ip, hosts: make([]string, 0, 1+len(rec.AdditionalHosts))}
+
+		line.hosts = append(append(line.hosts, rec.Host), rec.AdditionalHosts...)
+
+		if e.opts.Comments && !e.opts.Group {
+			line.comment = rec.Comment
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
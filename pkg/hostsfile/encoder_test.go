@@ -0,0 +1,155 @@
+package hostsfile
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoder_Encode(t *testing.T) {
+	var records = []Record{
+		{IP: "0.0.0.0", Host: "a.com", AdditionalHosts: []string{"www.a.com"}, Comment: "ads"},
+		{IP: "::1", Host: "localhost"},
+		{IP: "0.0.0.0", Host: "b.com", Comment: "tracking"},
+		{Host: "no-ip.com"},
+		{Host: "exception.com", Exception: true},
+		{IP: "0.0.0.0", Host: "c.com", AdditionalHosts: []string{"d.com", "e.com"}},
+	}
+
+	for name, tt := range map[string]struct {
+		giveOptions []EncoderOptions
+		wantOutput  string
+	}{
+		"defaults": {
+			wantOutput: `0.0.0.0 a.com www.a.com
+::1 localhost
+0.0.0.0 b.com
+0.0.0.0 c.com d.com e.com
+`,
+		},
+		"all options": {
+			giveOptions: []EncoderOptions{{
+				Header:          []string{"Title: foo", "", "Updated: today  "},
+				DefaultIP:       "127.0.0.1",
+				Align:           true,
+				Group:           true,
+				MaxHostsPerLine: 2,
+				Comments:        true, // ignored for grouped lines
+			}},
+			wantOutput: `# Title: foo
+#
+# Updated: today
+
+0.0.0.0   a.com www.a.com
+0.0.0.0   b.com c.com
+0.0.0.0   d.com e.com
+::1       localhost
+127.0.0.1 no-ip.com
+`,
+		},
+		"comments and alignment": {
+			giveOptions: []EncoderOptions{{Align: true, Comments: true}},
+			wantOutput: `0.0.0.0 a.com www.a.com # ads
+::1     localhost
+0.0.0.0 b.com # tracking
+0.0.0.0 c.com d.com e.com
+`,
+		},
+		"max hosts per line": {
+			giveOptions: []EncoderOptions{{MaxHostsPerLine: 1, Comments: true}},
+			wantOutput: `0.0.0.0 a.com # ads
+0.0.0.0 www.a.com # ads
+::1 localhost
+0.0.0.0 b.com # tracking
+0.0.0.0 c.com
+0.0.0.0 d.com
+0.0.0.0 e.com
+`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			assert.NoError(t, NewEncoder(&buf, tt.giveOptions...).Encode(records))
+			assert.Equal(t, tt.wantOutput, buf.String())
+		})
+	}
+}
+
+func TestEncoder_EncodeEmpty(t *testing.T) {
+	var buf bytes.Buffer
+
+	assert.NoError(t, Write(&buf, nil))
+	assert.Empty(t, buf.String())
+
+	assert.NoError(t, Write(&buf, nil, EncoderOptions{Header: []string{"foo"}}))
+	assert.Equal(t, "# foo\n\n", buf.String())
+}
+
+type errWriter struct{ err error }
+
+func (w errWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestEncoder_EncodeWritingError(t *testing.T) {
+	var someErr = errors.New("foo")
+
+	assert.ErrorIs(t, Write(errWriter{someErr}, []Record{{IP: "0.0.0.0", Host: "a.com"}}), someErr)
+	assert.ErrorIs(t, Write(errWriter{someErr}, nil, EncoderOptions{Header: []string{"foo"}}), someErr)
+}
+
+func TestEncoder_RoundTrip(t *testing.T) {
+	for _, tt := range benchDataset {
+		t.Run(tt.filePath, func(t *testing.T) {
+			raw, err := os.ReadFile(tt.filePath)
+			assert.NoError(t, err)
+
+			records, err := Parse(bytes.NewReader(raw))
+			assert.NoError(t, err)
+
+			var buf bytes.Buffer
+
+			assert.NoError(t, Write(&buf, records, EncoderOptions{Align: true, Comments: true}))
+
+			parsed, err := Parse(&buf)
+			assert.NoError(t, err)
+
+			assert.Len(t, parsed, len(records))
+
+			for i := range parsed {
+				assert.Equal(t, records[i].IP, parsed[i].IP)
+				assert.Equal(t, records[i].Host, parsed[i].Host)
+				assert.Equal(t, records[i].AdditionalHosts, parsed[i].AdditionalHosts)
+				assert.Equal(t, records[i].Comment, parsed[i].Comment)
+			}
+		})
+	}
+}
+
+func BenchmarkEncoder_Encode(b *testing.B) {
+	b.ReportAllocs()
+
+	raw, err := os.ReadFile("../../test/testdata/hosts/hosts_adaway.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	records, err := Parse(bytes.NewReader(raw))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		buf.Reset()
+
+		if err = Write(&buf, records, EncoderOptions{Group: true, MaxHostsPerLine: 9}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
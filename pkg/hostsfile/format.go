@@ -0,0 +1,48 @@
+package hostsfile
+
+import (
+	"errors"
+	"io"
+)
+
+// Format is a hosts list (source) format.
+type Format string
+
+const (
+	// FormatAuto means that the format should be detected automatically (using the content sample).
+	FormatAuto Format = "auto"
+
+	// FormatHosts is a classic hosts file format (`127.0.0.1 example.com`).
+	FormatHosts Format = "hosts"
+
+	// FormatAdBlock is AdBlock/AdGuard-style domain rules format (`||example.com^`).
+	FormatAdBlock Format = "adblock"
+
+	// FormatDomains is a plain domains list format (one hostname per line, without IP address).
+	FormatDomains Format = "domains"
+
+	// FormatDnsmasq is a dnsmasq configuration format (`address=/example.com/0.0.0.0`).
+	FormatDnsmasq Format = "dnsmasq"
+
+	// FormatRPZ is a DNS Response Policy Zone file format (`example.com CNAME .`).
+	FormatRPZ Format = "rpz"
+)
+
+// ErrUnsupportedFormat means that the passed format is not supported.
+var ErrUnsupportedFormat = errors.New("unsupported hosts list format")
+
+// ParseFormat validates passed string and returns the hosts list format. Empty string means FormatAuto.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case "":
+		return FormatAuto, nil
+
+	case FormatAuto, FormatHosts, FormatAdBlock, FormatDomains, FormatDnsmasq, FormatRPZ:
+		return f, nil
+	}
+
+	return "", ErrUnsupportedFormat
+}
+
+// ParseAs parses the input using the parser for passed format.
+func ParseAs(in io.Reader, f Format) ([]Record, error) { return parseAll(NewScanner(in, f)) }
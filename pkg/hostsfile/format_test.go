@@ -0,0 +1,47 @@
+package hostsfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFormat(t *testing.T) {
+	for give, want := range map[string]Format{
+		"":        FormatAuto,
+		"auto":    FormatAuto,
+		"hosts":   FormatHosts,
+		"adblock": FormatAdBlock,
+		"domains": FormatDomains,
+		"dnsmasq": FormatDnsmasq,
+		"rpz":     FormatRPZ,
+	} {
+		f, err := ParseFormat(give)
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, f)
+	}
+
+	_, err := ParseFormat("foo")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestParseAs(t *testing.T) {
+	const content = "0.0.0.0 foo.com\n||bar.com^\nbaz.com\n"
+
+	records, err := ParseAs(strings.NewReader(content), FormatHosts)
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{{IP: "0.0.0.0", Host: "foo.com", Line: 1}}, records)
+
+	records, err = ParseAs(strings.NewReader(content), FormatAdBlock)
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{{Host: "bar.com", Line: 2}}, records)
+
+	records, err = ParseAs(strings.NewReader(content), FormatDomains)
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{{Host: "baz.com", Line: 3}}, records)
+
+	_, err = ParseAs(strings.NewReader(content), Format("foo"))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
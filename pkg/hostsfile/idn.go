@@ -0,0 +1,42 @@
+package hostsfile
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// hostname validates the hostname and returns it as a string. Internationalized domain names (hostnames with
+// non-ASCII characters) are converted into the ASCII form (punycode A-labels, lowercased) using UTS #46 processing.
+// Trailing dot (fully qualified domain name) is allowed and kept as is (use Normalize for the canonical form).
+func hostname(b []byte) (string, bool) {
+	if l := len(b); l > 1 && b[l-1] == '.' && b[l-2] != '.' { // fully qualified domain name
+		host, ok := hostname(b[:l-1])
+		if !ok {
+			return "", false
+		}
+
+		return host + ".", true
+	}
+
+	for i := range b {
+		if b[i] >= utf8.RuneSelf {
+			return idnHostname(string(b))
+		}
+	}
+
+	if validateHostname(b) <= 0 {
+		return "", false
+	}
+
+	return string(b), true
+}
+
+func idnHostname(s string) (string, bool) {
+	ascii, err := idna.Lookup.ToASCII(s)
+	if err != nil || validateHostname([]byte(ascii)) <= 0 {
+		return "", false
+	}
+
+	return ascii, true
+}
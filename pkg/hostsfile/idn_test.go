@@ -0,0 +1,68 @@
+package hostsfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostname(t *testing.T) {
+	for name, tt := range map[string]struct {
+		give     string
+		wantHost string
+		wantOk   bool
+	}{
+		"ascii":                  {give: "example.com", wantHost: "example.com", wantOk: true},
+		"ascii with underscores": {give: "___id___.c.mystat-in.net", wantHost: "___id___.c.mystat-in.net", wantOk: true},
+		"punycode":               {give: "xn--e1aybc.xn--p1ai", wantHost: "xn--e1aybc.xn--p1ai", wantOk: true},
+		"cyrillic":               {give: "тест.рф", wantHost: "xn--e1aybc.xn--p1ai", wantOk: true},
+		"cyrillic upper case":    {give: "ТЕСТ.РФ", wantHost: "xn--e1aybc.xn--p1ai", wantOk: true},
+		"full-width dot":         {give: "тест。рф", wantHost: "xn--e1aybc.xn--p1ai", wantOk: true},
+		"mixed":                  {give: "Bücher.example.com", wantHost: "xn--bcher-kva.example.com", wantOk: true},
+		"latin small capital":    {give: "secret.ɢoogle.com", wantHost: "secret.xn--oogle-wmc.com", wantOk: true},
+		"trailing dot":           {give: "example.com.", wantHost: "example.com.", wantOk: true},
+		"idn with trailing dot":  {give: "тест.рф.", wantHost: "xn--e1aybc.xn--p1ai.", wantOk: true},
+		"double trailing dot":    {give: "example.com..", wantHost: "", wantOk: false},
+		"dot only":               {give: ".", wantHost: "", wantOk: false},
+		"empty label":            {give: "тест..рф", wantHost: "", wantOk: false},
+		"with space":             {give: "тест рф", wantHost: "", wantOk: false},
+		"invalid ascii":          {give: "foo@bar.com", wantHost: "", wantOk: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			host, ok := hostname([]byte(tt.give))
+
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantHost, host)
+		})
+	}
+}
+
+func TestParseIDN(t *testing.T) {
+	records, err := ParseAs(strings.NewReader("0.0.0.0 пример.рф www.Пример.РФ\n"), FormatHosts)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{{
+		IP:              "0.0.0.0",
+		Host:            "xn--e1afmkfd.xn--p1ai",
+		AdditionalHosts: []string{"www.xn--e1afmkfd.xn--p1ai"},
+		Line:            1,
+	}}, records)
+
+	for _, f := range []Format{FormatDomains, FormatAdBlock, FormatDnsmasq, FormatRPZ} {
+		var line = map[Format]string{
+			FormatDomains: "пример.рф",
+			FormatAdBlock: "||пример.рф^",
+			FormatDnsmasq: "address=/пример.рф/0.0.0.0",
+			FormatRPZ:     "пример.рф CNAME .",
+		}[f]
+
+		records, err = ParseAs(strings.NewReader(line+"\n"), f)
+
+		assert.NoError(t, err)
+
+		if assert.Len(t, records, 1, f) {
+			assert.Equal(t, "xn--e1afmkfd.xn--p1ai", records[0].Host, f)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package hostsfile
+
+import "strings"
+
+// Normalize returns the canonical form of the hostname - lowercased, without trailing dot and (optionally, when
+// collapseWWW is true) without the `www.` prefix. Canonical hostnames are returned as is, without any allocations.
+func Normalize(host string, collapseWWW bool) string {
+	var lower = true
+
+	for i := 0; i < len(host); i++ {
+		if c := host[i]; 'A' <= c && c <= 'Z' {
+			lower = false
+
+			break
+		}
+	}
+
+	if !lower {
+		host = strings.ToLower(host)
+	}
+
+	if l := len(host); l > 1 && host[l-1] == '.' {
+		host = host[:l-1]
+	}
+
+	if collapseWWW && len(host) > 4 && strings.HasPrefix(host, "www.") && strings.IndexByte(host[4:], '.') > 0 {
+		host = host[4:] // `www.example.com` but not `www.com`
+	}
+
+	return host
+}
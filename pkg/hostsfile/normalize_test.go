@@ -0,0 +1,42 @@
+package hostsfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	for name, tt := range map[string]struct {
+		giveHost        string
+		giveCollapseWWW bool
+		wantHost        string
+	}{
+		"canonical":                   {giveHost: "example.com", wantHost: "example.com"},
+		"upper case":                  {giveHost: "Example.COM", wantHost: "example.com"},
+		"trailing dot":                {giveHost: "example.com.", wantHost: "example.com"},
+		"upper case with dot":         {giveHost: "EXAMPLE.com.", wantHost: "example.com"},
+		"www is kept":                 {giveHost: "www.example.com", wantHost: "www.example.com"},
+		"www collapsed":               {giveHost: "WWW.Example.com.", giveCollapseWWW: true, wantHost: "example.com"},
+		"www without domain":          {giveHost: "www.com", giveCollapseWWW: true, wantHost: "www.com"},
+		"www only":                    {giveHost: "www.", giveCollapseWWW: true, wantHost: "www"},
+		"www inside":                  {giveHost: "a.www.example.com", giveCollapseWWW: true, wantHost: "a.www.example.com"},
+		"www prefix without dot":      {giveHost: "wwwexample.com", giveCollapseWWW: true, wantHost: "wwwexample.com"},
+		"underscores and punycode":    {giveHost: "_Foo.XN--P1AI", wantHost: "_foo.xn--p1ai"},
+		"single dot":                  {giveHost: ".", wantHost: "."},
+		"empty":                       {giveHost: "", wantHost: ""},
+		"double www collapsed (once)": {giveHost: "www.www.example.com", giveCollapseWWW: true, wantHost: "www.example.com"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.wantHost, Normalize(tt.giveHost, tt.giveCollapseWWW))
+		})
+	}
+}
+
+func BenchmarkNormalize(b *testing.B) {
+	b.ReportAllocs()
+
+	for n := 0; n < b.N; n++ {
+		_ = Normalize("www.example.com", false)
+	}
+}
@@ -1,7 +1,6 @@
 package hostsfile
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/binary"
 	"io"
@@ -47,104 +46,128 @@ func (w *word) Reset() {
 	w.buf.Reset()
 }
 
-// Parse input and return slice of records. Result order are same as in source.
+// Parse input (in hosts file format) and return slice of records. Result order are same as in source.
+func Parse(in io.Reader) ([]Record, error) { return parseAll(NewScanner(in, FormatHosts)) }
+
+// hostsParser parses hosts file lines. Buffers are reused between the lines (allocation avoiding reasons).
+type hostsParser struct {
+	w         word
+	hostnames []string
+	ip        bytes.Buffer
+}
+
+func newHostsParser() *hostsParser {
+	var p = &hostsParser{hostnames: make([]string, 0, 3)}
+
+	p.w.buf.Grow(32)
+	p.ip.Grow(7)
+
+	return p
+}
+
+// parseLine parses single hosts file line. False will be returned for invalid or comment lines.
 //
 //nolint:funlen,gocognit,gocyclo,wsl_v5 // compact parser control flow is easier to follow without extra blank lines
-func Parse(in io.Reader) ([]Record, error) {
-	var (
-		result    = make([]Record, 0, 5)
-		scan      = bufio.NewScanner(in)
-		w         word
-		hostnames = make([]string, 0, 3)
-		ip        bytes.Buffer
-	)
+func (p *hostsParser) parseLine(line []byte) (Record, bool) {
+	if len(line) <= 5 {
+		return Record{}, false // line is too short
+	}
 
-	w.buf.Grow(32)
-	ip.Grow(7)
+	if line[0] == '#' {
+		return Record{}, false // skip any lines, that looks like comments in format: `# Any comment text`
+	}
 
-scan: // read content "line by line"
-	for scan.Scan() {
-		line := scan.Bytes()
+	var (
+		w, ip   = &p.w, &p.ip
+		comment []byte // inline comment (with the leading `#`)
+	)
 
-		if len(line) <= 5 {
-			continue scan // line is too short
-		}
+	w.Reset()
+	ip.Reset()
+	if len(p.hostnames) > 0 {
+		p.hostnames = p.hostnames[:0]
+	}
 
-		if line[0] == '#' {
-			continue scan // skip any lines, that looks like comments in format: `# Any comment text`
-		}
+	for i, ll := 0, len(line); i < ll && !w.isLast; i++ { // loop over line runes
+		if char := line[i]; char != ' ' && char != '\t' {
+			switch char {
+			case '.':
+				w.flag.AddFlag(wordWithDot)
+			case ':':
+				w.flag.AddFlag(wordWithColon)
+			}
 
-		w.Reset()
-		ip.Reset()
-		if len(hostnames) > 0 {
-			hostnames = hostnames[:0]
+			w.buf.WriteByte(char)
+			w.flag.ClearFlag(wordEnded)
+		} else {
+			w.flag.AddFlag(wordEnded)
 		}
 
-		for i, ll := 0, len(line); i < ll && !w.isLast; i++ { // loop over line runes
-			if char := line[i]; char != ' ' && char != '\t' {
-				switch char {
-				case '.':
-					w.flag.AddFlag(wordWithDot)
-				case ':':
-					w.flag.AddFlag(wordWithColon)
-				}
-
-				w.buf.WriteByte(char)
-				w.flag.ClearFlag(wordEnded)
-			} else {
-				w.flag.AddFlag(wordEnded)
+		if w.flag.HasFlag(wordEnded) || i == ll-1 { //nolint:nestif // word filled completely
+			if w.buf.Len() == 0 {
+				continue // skip any empty words
 			}
 
-			if w.flag.HasFlag(wordEnded) || i == ll-1 { //nolint:nestif // word filled completely
-				if w.buf.Len() == 0 {
-					continue // skip any empty words
-				}
+			w.count++
 
-				w.count++
+			if w.count == 1 && w.buf.Bytes()[0] == '#' {
+				return Record{}, false // skip if first word starts with comment char
+			}
 
-				if w.count == 1 && w.buf.Bytes()[0] == '#' {
-					continue scan // skip if first word starts with comment char
+			if w.count == 1 {
+				if (w.flag.HasFlag(wordWithDot) && validateIPv4(w.buf.Bytes())) ||
+					(w.flag.HasFlag(wordWithColon) && net.ParseIP(w.buf.String()) != nil) {
+					ip.Write(w.buf.Bytes())
+				} else if !w.flag.HasFlag(wordWithDot) && !w.flag.HasFlag(wordWithColon) {
+					if long, ok := parseLongIP(w.buf.String()); ok {
+						ip.WriteString(long.To4().String())
+					}
 				}
+			} else {
+				if w.buf.Bytes()[0] == '#' { // comment at the end of line
+					w.isLast = true
 
-				if w.count == 1 {
-					if (w.flag.HasFlag(wordWithDot) && validateIPv4(w.buf.Bytes())) ||
-						(w.flag.HasFlag(wordWithColon) && net.ParseIP(w.buf.String()) != nil) {
-						ip.Write(w.buf.Bytes())
-					} else if !w.flag.HasFlag(wordWithDot) && !w.flag.HasFlag(wordWithColon) {
-						if long, ok := parseLongIP(w.buf.String()); ok {
-							ip.WriteString(long.To4().String())
-						}
+					if end := i + 1; w.flag.HasFlag(wordEnded) { // word ends before the current (space) char
+						comment = line[end-1-w.buf.Len():]
+					} else {
+						comment = line[end-w.buf.Len():]
 					}
-				} else {
-					if w.buf.Bytes()[0] == '#' { // comment at the end of line
-						w.isLast = true
-					} else if ip.Len() > 0 && validateHostname(w.buf.Bytes()) > 0 {
-						hostnames = append(hostnames, w.buf.String()) // +1 memory allocation here
+				} else if ip.Len() > 0 {
+					if host, ok := hostname(w.buf.Bytes()); ok {
+						p.hostnames = append(p.hostnames, host) // +1 memory allocation here
 					}
 				}
-
-				w.buf.Reset()
-				w.flag.Reset()
 			}
+
+			w.buf.Reset()
+			w.flag.Reset()
 		}
+	}
 
-		if ip.Len() > 0 && len(hostnames) > 0 {
-			rec := Record{IP: ip.String(), Host: hostnames[0]} // +1 memory allocation here
+	if ip.Len() == 0 || len(p.hostnames) == 0 {
+		return Record{}, false
+	}
 
-			if l := len(hostnames); l > 1 {
-				rec.AdditionalHosts = make([]string, 0, l-1) // +1 memory allocation here (but not for each record)
-				rec.AdditionalHosts = append(rec.AdditionalHosts, hostnames[1:]...)
-			}
+	rec := Record{IP: ip.String(), Host: p.hostnames[0], Comment: inlineComment(comment)} // +1 memory allocation here
 
-			result = append(result, rec)
-		}
+	if l := len(p.hostnames); l > 1 {
+		rec.AdditionalHosts = make([]string, 0, l-1) // +1 memory allocation here (but not for each record)
+		rec.AdditionalHosts = append(rec.AdditionalHosts, p.hostnames[1:]...)
 	}
 
-	if err := scan.Err(); err != nil {
-		return nil, err
+	return rec, true
+}
+
+// inlineComment returns the comment text without leading comment chars and spaces (no allocations for the empty
+// comments).
+func inlineComment(b []byte) string {
+	b = bytes.TrimSpace(bytes.TrimLeft(b, "#; \t"))
+
+	if len(b) == 0 {
+		return ""
 	}
 
-	return result, nil
+	return string(b)
 }
 
 // validateIPv4 address (d.d.d.d).
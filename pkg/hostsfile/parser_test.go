@@ -76,9 +76,9 @@ func TestParseUsingHostsFileContent(t *testing.T) {
 			wantHostNames: 1106,
 		},
 		{
-			giveFilePath:  "../../test/testdata/hosts/hosts_someonewhocares.txt", // broken entry `127.0.0.1 secret.ɢoogle.com`
-			wantRecords:   14308,
-			wantHostNames: 14309, // ::1 [ip6-localhost ip6-loopback]
+			giveFilePath:  "../../test/testdata/hosts/hosts_someonewhocares.txt", // IDN entry `127.0.0.1 secret.ɢoogle.com`
+			wantRecords:   14309,
+			wantHostNames: 14310, // ::1 [ip6-localhost ip6-loopback]
 		},
 		{
 			giveFilePath:  "../../test/testdata/hosts/hosts_winhelp2002.txt",
@@ -198,7 +198,7 @@ the end
 	// "тест.рф" must be encoded as `xn--e1aybc.xn--p1ai`
 	assert.Equal(t, "3.3.3.3", records[8].IP)
 	assert.Equal(t, "xn--e1aybc.xn--p1ai", records[8].Host)
-	assert.Nil(t, records[8].AdditionalHosts)
+	assert.Equal(t, []string{"xn--e1aybc.xn--p1ai"}, records[8].AdditionalHosts)
 
 	assert.Equal(t, "0.0.0.0", records[9].IP) // long: 0
 	assert.Equal(t, "min.long.integer.ip", records[9].Host)
@@ -208,3 +208,26 @@ the end
 	assert.Equal(t, "max.long.integer.ip", records[10].Host)
 	assert.Nil(t, records[7].AdditionalHosts)
 }
+
+func TestParseInlineComments(t *testing.T) {
+	records, err := Parse(bytes.NewBufferString(`# comment
+1.1.1.1 foo.com # malware
+2.2.2.2 bar.com	#[ads]   tracking
+3.3.3.3 baz.com #
+4.4.4.4 qux.com ## double hash
+5.5.5.5 quux.com #no-space
+6.6.6.6 a.com b.com # multiple hosts #with hash
+7.7.7.7 corge.com
+`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{
+		{IP: "1.1.1.1", Host: "foo.com", Comment: "malware", Line: 2},
+		{IP: "2.2.2.2", Host: "bar.com", Comment: "[ads]   tracking", Line: 3},
+		{IP: "3.3.3.3", Host: "baz.com", Line: 4},
+		{IP: "4.4.4.4", Host: "qux.com", Comment: "double hash", Line: 5},
+		{IP: "5.5.5.5", Host: "quux.com", Comment: "no-space", Line: 6},
+		{IP: "6.6.6.6", Host: "a.com", AdditionalHosts: []string{"b.com"}, Comment: "multiple hosts #with hash", Line: 7},
+		{IP: "7.7.7.7", Host: "corge.com", Line: 8},
+	}, records)
+}
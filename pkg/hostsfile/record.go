@@ -5,4 +5,8 @@ type Record struct {
 	IP              string
 	Host            string
 	AdditionalHosts []string
+	Exception       bool   // the record is an exception (allowlist) rule (AdBlock-style `@@||example.com^`)
+	Important       bool   // the record has priority over the exception rules (AdBlock-style `$important` modifier)
+	Comment         string // inline comment text (eg.: `0.0.0.0 example.com # malware` - `malware`)
+	Line            int    // source line number (starting from 1), filled by the Scanner
 }
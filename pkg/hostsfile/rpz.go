@@ -0,0 +1,103 @@
+package hostsfile
+
+import (
+	"bytes"
+	"io"
+	"net"
+)
+
+// ParseRPZ parses the DNS Response Policy Zone (RPZ) file and returns slice of records. Result order are same as in
+// source.
+//
+// Supported policy records are:
+//
+//	example.com CNAME .               - NXDOMAIN action (empty IP address)
+//	example.com CNAME *.              - NODATA action (empty IP address)
+//	example.com A 0.0.0.0             - local data (IP address is set)
+//	example.com AAAA ::               - local data (IP address is set)
+//	example.com CNAME rpz-passthru.   - PASSTHRU action (Record.Exception is true)
+//
+// Wildcard records, absolute names outside of the `$ORIGIN` and any other records types are ignored.
+func ParseRPZ(in io.Reader) ([]Record, error) { return parseAll(NewScanner(in, FormatRPZ)) }
+
+// rpzParser keeps the zone file parsing state between the lines.
+type rpzParser struct {
+	origin     []byte // current `$ORIGIN` value (with the leading dot, eg.: `.rpz.local.`)
+	inBrackets bool   // multi-line record (like SOA) is in progress
+}
+
+// parseLine parses single zone file line. False will be returned for unsupported, invalid or comment lines.
+//
+//nolint:gocyclo // flat zone file syntax rules are easier to follow in one place
+func (p *rpzParser) parseLine(line []byte) (Record, bool) {
+	var comment []byte
+
+	if i := bytes.IndexByte(line, ';'); i >= 0 {
+		line, comment = line[:i], line[i:] // cut the comment
+	}
+
+	if p.inBrackets { // skip multi-line record content
+		p.inBrackets = !bytes.ContainsRune(line, ')')
+
+		return Record{}, false
+	}
+
+	if bytes.ContainsRune(line, '(') && !bytes.ContainsRune(line, ')') {
+		p.inBrackets = true
+
+		return Record{}, false
+	}
+
+	if len(line) == 0 || line[0] == ' ' || line[0] == '\t' {
+		return Record{}, false // empty lines and records without owner name (previous owner is used) are ignored
+	}
+
+	fields := bytes.Fields(line)
+	if len(fields) < 3 { // name, type and data are required
+		if len(fields) == 2 && bytes.EqualFold(fields[0], []byte("$ORIGIN")) {
+			p.origin = append(append(p.origin[:0], '.'), bytes.ToLower(fields[1])...)
+		}
+
+		return Record{}, false
+	}
+
+	name, rrType, data := fields[0], fields[len(fields)-2], fields[len(fields)-1]
+
+	if name[len(name)-1] == '.' { // absolute name
+		if len(p.origin) == 0 || !bytes.HasSuffix(bytes.ToLower(name), p.origin) {
+			return Record{}, false
+		}
+
+		name = name[:len(name)-len(p.origin)]
+	}
+
+	host, ok := hostname(name)
+	if !ok {
+		return Record{}, false // wildcards, `@` and invalid names
+	}
+
+	var rec = Record{Host: host, Comment: inlineComment(comment)}
+
+	switch {
+	case bytes.EqualFold(rrType, []byte("CNAME")):
+		switch {
+		case bytes.Equal(data, []byte(".")), bytes.Equal(data, []byte("*.")):
+		case bytes.EqualFold(data, []byte("rpz-passthru.")):
+			rec.Exception = true
+		default:
+			return Record{}, false
+		}
+
+	case bytes.EqualFold(rrType, []byte("A")), bytes.EqualFold(rrType, []byte("AAAA")):
+		if net.ParseIP(string(data)) == nil {
+			return Record{}, false
+		}
+
+		rec.IP = string(data)
+
+	default:
+		return Record{}, false
+	}
+
+	return rec, true
+}
@@ -0,0 +1,26 @@
+package hostsfile
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRPZ(t *testing.T) {
+	file, err := os.Open("../../test/testdata/hosts/rpz.zone")
+	assert.NoError(t, err)
+
+	records, parseErr := ParseRPZ(file)
+	assert.NoError(t, file.Close())
+	assert.NoError(t, parseErr)
+
+	assert.Equal(t, []Record{
+		{Host: "ads.example.com", Line: 11},
+		{Host: "tracker.example.org", Line: 12},
+		{IP: "0.0.0.0", Host: "metrics.example.net", Line: 13},
+		{IP: "::", Host: "metrics.example.net", Line: 14},
+		{Host: "good.example.com", Exception: true, Line: 15},
+		{Host: "absolute.example.com", Line: 18},
+	}, records)
+}
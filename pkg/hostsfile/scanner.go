@@ -0,0 +1,145 @@
+package hostsfile
+
+import (
+	"bufio"
+	"io"
+)
+
+// lineParser parses hosts list lines one by one. False must be returned for the lines without records.
+type lineParser interface {
+	parseLine(line []byte) (Record, bool)
+}
+
+// lineParserFunc is an adapter to allow the use of ordinary (stateless) functions as line parsers.
+type lineParserFunc func(line []byte) (Record, bool)
+
+func (f lineParserFunc) parseLine(line []byte) (Record, bool) { return f(line) }
+
+// Scanner reads the hosts list records one by one, without the whole records list building in memory. Successive
+// calls to the Next method will step through the records. Example:
+//
+//	s := hostsfile.NewScanner(in, hostsfile.FormatAuto)
+//
+//	for s.Next() {
+//		rec := s.Record()
+//		// ...
+//	}
+//
+//	if err := s.Err(); err != nil {
+//		// ...
+//	}
+type Scanner struct {
+	scan   *bufio.Scanner
+	parser lineParser
+	format Format
+	record Record
+	line   int          // current line number
+	diag   *Diagnostics // optional, nil if diagnostics is disabled
+	err    error
+}
+
+// NewScanner creates a new scanner for the input with passed format. FormatAuto can be used for the format
+// detection (using the first part of the input).
+func NewScanner(in io.Reader, f Format) *Scanner {
+	var s = &Scanner{format: f}
+
+	if f == FormatAuto {
+		buf := bufio.NewReaderSize(in, detectSampleSize)
+		sample, _ := buf.Peek(detectSampleSize) // errors are not important here (the sample can be shorter)
+
+		in, s.format = buf, DetectFormat(sample)
+	}
+
+	switch s.format {
+	case FormatHosts:
+		s.parser = newHostsParser()
+	case FormatAdBlock:
+		s.parser = lineParserFunc(parseAdBlockRule)
+	case FormatDomains:
+		s.parser = lineParserFunc(parseDomainLine)
+	case FormatDnsmasq:
+		s.parser = lineParserFunc(parseDnsmasqLine)
+	case FormatRPZ:
+		s.parser = &rpzParser{}
+	case FormatAuto: // unreachable (the format is already detected)
+		fallthrough
+	default:
+		s.err = ErrUnsupportedFormat
+
+		return s
+	}
+
+	s.scan = bufio.NewScanner(in)
+
+	return s
+}
+
+// Next advances the scanner to the next record, which will then be available through the Record method. It returns
+// false when the scan stops, either by reaching the end of the input or an error.
+func (s *Scanner) Next() bool {
+	if s.err != nil {
+		return false
+	}
+
+	for s.scan.Scan() {
+		s.line++
+
+		if rec, ok := s.parser.parseLine(s.scan.Bytes()); ok {
+			rec.Line = s.line
+			s.record = rec
+
+			if s.diag != nil {
+				s.diag.Lines, s.diag.Records = s.line, s.diag.Records+1
+			}
+
+			return true
+		}
+
+		if s.diag != nil {
+			s.diag.Lines = s.line
+			s.diag.skip(s.line, skipReason(s.format, s.scan.Bytes()), s.scan.Bytes())
+		}
+	}
+
+	s.err = s.scan.Err()
+
+	return false
+}
+
+// Record returns the most recent record generated by a call to Next.
+func (s *Scanner) Record() Record { return s.record }
+
+// Line returns the number of line (starting from 1) with the most recent record.
+func (s *Scanner) Line() int { return s.line }
+
+// EnableDiagnostics enables the skipped lines collecting (it should be called before the first Next call). Collected
+// data can be accessed using the Diagnostics method.
+func (s *Scanner) EnableDiagnostics() {
+	if s.diag == nil {
+		s.diag = newDiagnostics()
+	}
+}
+
+// Diagnostics returns collected diagnostics data (nil will be returned if diagnostics was not enabled).
+func (s *Scanner) Diagnostics() *Diagnostics { return s.diag }
+
+// Err returns the first non-EOF error that was encountered by the Scanner.
+func (s *Scanner) Err() error { return s.err }
+
+// Format returns the scanner input format (detected format, if FormatAuto was used).
+func (s *Scanner) Format() Format { return s.format }
+
+// parseAll reads all the records using the scanner.
+func parseAll(s *Scanner) ([]Record, error) {
+	var result = make([]Record, 0, 5)
+
+	for s.Next() {
+		result = append(result, s.Record())
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
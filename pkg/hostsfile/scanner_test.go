@@ -0,0 +1,121 @@
+package hostsfile
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func BenchmarkScanner(b *testing.B) {
+	for _, tt := range benchDataset {
+		b.Run(filepath.Base(tt.filePath), func(b *testing.B) {
+			b.ReportAllocs()
+
+			raw, err := os.ReadFile(tt.filePath)
+			if err != nil {
+				panic(err)
+			}
+
+			b.SetBytes(int64(len(raw)))
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				s := NewScanner(bytes.NewReader(raw), FormatHosts)
+
+				for s.Next() {
+					_ = s.Record()
+				}
+
+				if e := s.Err(); e != nil {
+					b.Fatal(e)
+				}
+			}
+		})
+	}
+}
+
+func TestScanner(t *testing.T) {
+	var s = NewScanner(strings.NewReader(`# comment
+1.1.1.1 foo.com
+
+2.2.2.2 bar.com baz.com
+broken line
+`), FormatHosts)
+
+	assert.Equal(t, FormatHosts, s.Format())
+
+	assert.True(t, s.Next())
+	assert.Equal(t, Record{IP: "1.1.1.1", Host: "foo.com", Line: 2}, s.Record())
+
+	assert.True(t, s.Next())
+	assert.Equal(t, Record{IP: "2.2.2.2", Host: "bar.com", AdditionalHosts: []string{"baz.com"}, Line: 4}, s.Record())
+
+	assert.False(t, s.Next())
+	assert.False(t, s.Next()) // repeated calls are safe
+	assert.NoError(t, s.Err())
+}
+
+func TestScannerFormatDetection(t *testing.T) {
+	var s = NewScanner(strings.NewReader("! comment\n||foo.com^\n@@||bar.com^\n"), FormatAuto)
+
+	assert.Equal(t, FormatAdBlock, s.Format())
+
+	var records []Record
+
+	for s.Next() {
+		records = append(records, s.Record())
+	}
+
+	assert.NoError(t, s.Err())
+	assert.Equal(t, []Record{{Host: "foo.com", Line: 2}, {Host: "bar.com", Exception: true, Line: 3}}, records)
+}
+
+func TestScannerUnsupportedFormat(t *testing.T) {
+	var s = NewScanner(strings.NewReader("1.1.1.1 foo.com\n"), Format("foo"))
+
+	assert.False(t, s.Next())
+	assert.ErrorIs(t, s.Err(), ErrUnsupportedFormat)
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestScannerReadingError(t *testing.T) {
+	var someErr = errors.New("foo")
+
+	var s = NewScanner(errReader{err: someErr}, FormatHosts)
+
+	assert.False(t, s.Next())
+	assert.ErrorIs(t, s.Err(), someErr)
+}
+
+func TestScannerUsingHostsFileContent(t *testing.T) {
+	for _, tt := range benchDataset {
+		t.Run(tt.filePath, func(t *testing.T) {
+			raw, err := os.ReadFile(tt.filePath)
+			assert.NoError(t, err)
+
+			expected, err := Parse(bytes.NewReader(raw))
+			assert.NoError(t, err)
+
+			var (
+				s      = NewScanner(bytes.NewReader(raw), FormatAuto)
+				actual = make([]Record, 0, len(expected))
+			)
+
+			for s.Next() {
+				actual = append(actual, s.Record())
+			}
+
+			assert.NoError(t, s.Err())
+			assert.Equal(t, FormatHosts, s.Format())
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
@@ -42,6 +42,26 @@ func BenchmarkDNSStaticEntries_Render(b *testing.B) {
 	}
 }
 
+func BenchmarkDNSStaticEntries_RenderWithEscaping(b *testing.B) {
+	b.ReportAllocs()
+
+	data := make(DNSStaticEntries, 0, 1000)
+
+	for range 1000 {
+		data = append(data, DNSStaticEntry{Address: "0.0.0.0", Comment: "Any text", Name: "www.example.com"})
+	}
+
+	dest := bytes.NewBuffer([]byte{})
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		dest.Reset()
+
+		_, _ = data.Render(dest, RenderingOptions{Prefix: "add", Escape: true})
+	}
+}
+
 //nolint:goconst // repeated fixture values keep rendering expectations explicit
 func TestDNSStaticEntries_Render(t *testing.T) {
 	tests := []struct {
@@ -111,6 +131,42 @@ func TestDNSStaticEntries_Render(t *testing.T) {
 			}},
 			wantResult: `address=1.2.3.4 comment="foo comment" disabled=yes name="Bar name" regexp=".*\.example\.com" ttl="1d"`, //nolint:lll
 		},
+		{
+			name: "without escaping",
+			giveEntries: DNSStaticEntries{{
+				Address: "0.0.0.0",
+				Comment: `foo "bar" $baz`,
+				Name:    "foo.com",
+			}},
+			wantResult: `address=0.0.0.0 comment="foo "bar" $baz" disabled=no name="foo.com"`,
+		},
+		{
+			name: "with escaping",
+			giveEntries: DNSStaticEntries{{
+				Address: "0.0.0.0",
+				Comment: "foo \"bar\" $baz?\n\t\\",
+				Name:    "foo.com",
+				Regexp:  `.*\.example\.com`,
+				TTL:     "1d",
+			}},
+			giveOptions: RenderingOptions{Prefix: "add", Escape: true},
+			wantResult: `add address=0.0.0.0 comment="foo \"bar\" \$baz\?\0A\09\\" disabled=no name="foo.com" ` +
+				`regexp=".*\\.example\\.com" ttl="1d"`,
+		},
+		{
+			name: "with guards",
+			giveEntries: DNSStaticEntries{{
+				Address: "0.0.0.0",
+				Name:    `foo"bar.com`,
+			}, {}, {
+				Regexp: `.*\.example\.com$`,
+				Type:   TypeNXDOMAIN,
+			}},
+			giveOptions: RenderingOptions{Prefix: "add", Escape: true, Guard: true},
+			wantResult: `:if ([:len [find name="foo\"bar.com"]] = 0) do={add address=0.0.0.0 disabled=no name="foo\"bar.com"}` +
+				"\n" + `:if ([:len [find regexp=".*\\.example\\.com\$"]] = 0) do={add disabled=no ` +
+				`regexp=".*\\.example\\.com\$" type=NXDOMAIN}`,
+		},
 		{
 			name: "regular use-case with address, name and comment",
 			giveEntries: DNSStaticEntries{{
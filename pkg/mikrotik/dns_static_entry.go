@@ -1,63 +1,153 @@
 package mikrotik
 
+// EntryType is a static DNS entry type (RouterOS v7+). Empty type means the default one (`A`).
+type EntryType string
+
+// Supported static DNS entry types.
+const (
+	TypeA        EntryType = "A"        // IPv4 address (Address is required)
+	TypeAAAA     EntryType = "AAAA"     // IPv6 address (Address is required)
+	TypeCNAME    EntryType = "CNAME"    // canonical name (CNAME is required)
+	TypeFWD      EntryType = "FWD"      // forward the query to another DNS server (ForwardTo is required)
+	TypeNXDOMAIN EntryType = "NXDOMAIN" // answer with the "non-existent domain" response
+	TypeTXT      EntryType = "TXT"      // text record (Text is required)
+)
+
 // DNSStaticEntry is static DNS entry for RouterOS usage.
 type DNSStaticEntry struct {
-	Address  string // IP address (net.IP is not used for allocation avoiding reasons (to string), eg.: 0.0.0.0)
-	Comment  string // Short description of the item (eg.: Any text)
-	Disabled bool   // Defines whether item is ignored or used (eg.: yes,no)
-	Name     string // Host name (eg.: www.example.com)
-	Regexp   string // Regular expression (eg.: .*\\.example\\.com)
-	TTL      string // Time To Live (eg.: 1d)
+	Address        string    // IP address (net.IP is not used for allocation avoiding reasons (to string), eg.: 0.0.0.0)
+	AddressList    string    // Firewall address list name, resolved addresses will be added into (eg.: blocked)
+	CNAME          string    // Canonical name for the CNAME entries (eg.: example.com)
+	Comment        string    // Short description of the item (eg.: Any text)
+	Disabled       bool      // Defines whether item is ignored or used (eg.: yes,no)
+	ForwardTo      string    // DNS server address for the FWD entries (eg.: 1.1.1.1)
+	MatchSubdomain bool      // Entry is applied to the subdomains too (eg.: yes,no)
+	Name           string    // Host name (eg.: www.example.com)
+	Regexp         string    // Regular expression (eg.: .*\\.example\\.com)
+	Text           string    // Text for the TXT entries (eg.: v=spf1 -all)
+	TTL            string    // Time To Live (eg.: 1d)
+	Type           EntryType // Entry type (eg.: NXDOMAIN)
 }
 
 // Format entry as a text in RouterOS script format.
-// Important: keep im mind that any unexpected characters will be formatted as-is (without escaping or filtering).
+// Important: keep im mind that any unexpected characters will be formatted as-is (without escaping or filtering), use
+// DNSStaticEntries.Render with the RenderingOptions.Escape option for the escaping.
 //
 //nolint:wsl_v5 // compact buffer setup keeps this formatter straightforward
 func (s *DNSStaticEntry) Format(prefix, postfix string) ([]byte, error) {
 	const overSize = 96 // pre-allocation reserve
-	buf := make([]byte, 0, len(s.Address)+len(s.Comment)+len(s.Name)+len(s.Regexp)+len(s.TTL)+overSize)
-	err := s.format(&buf, prefix, postfix)
+	buf := make([]byte, 0, len(s.Address)+len(s.AddressList)+len(s.CNAME)+len(s.Comment)+len(s.ForwardTo)+
+		len(s.Name)+len(s.Regexp)+len(s.Text)+len(s.TTL)+overSize)
+	err := s.format(&buf, prefix, postfix, false)
 
 	return buf, err
 }
 
-// format documentation: <https://wiki.mikrotik.com/wiki/Manual:IP/DNS#Static_DNS_Entries>
-// Important: empty values will NOT be printed. Values escaping are not allowed here (reason - allocation avoiding).
-func (s *DNSStaticEntry) format(buf *[]byte, prefix, postfix string) error {
-	if s.Address == "" || (s.Name == "" && s.Regexp == "") {
+// validate checks the required (for the entry type) fields.
+func (s *DNSStaticEntry) validate() error {
+	if s.Name == "" && s.Regexp == "" {
 		return ErrEmptyFields
 	}
 
-	// write prefix
-	if len(prefix) > 0 {
-		*buf = append(*buf, prefix+" "...)
+	switch s.Type {
+	case "", TypeA, TypeAAAA:
+		if s.Address == "" {
+			return ErrEmptyFields
+		}
+
+	case TypeCNAME:
+		if s.CNAME == "" {
+			return ErrEmptyFields
+		}
+
+	case TypeFWD:
+		if s.ForwardTo == "" {
+			return ErrEmptyFields
+		}
+
+	case TypeTXT:
+		if s.Text == "" {
+			return ErrEmptyFields
+		}
+
+	case TypeNXDOMAIN:
+
+	default:
+		return ErrUnsupportedType
+	}
+
+	return nil
+}
+
+// format documentation: <https://help.mikrotik.com/docs/display/ROS/DNS#DNS-DNSStatic>
+// Important: empty values will NOT be printed. Quoted values are escaped only when escape is true (values without
+// special characters are appended as-is, without allocations).
+func (s *DNSStaticEntry) format(buf *[]byte, prefix, postfix string, escape bool) error { //nolint:funlen,gocyclo
+	if err := s.validate(); err != nil {
+		return err
 	}
 
+	var start = len(*buf)
+
+	// write prefix (every next field starts with the space)
+	*buf = append(*buf, prefix...)
+
 	// write "address"
-	*buf = append(*buf, `address=`+s.Address...) // quoting ("..") is needed here?
+	if s.Address != "" {
+		*buf = append(*buf, ` address=`+s.Address...) // quoting ("..") is needed here?
+	}
+
+	// write "address-list"
+	if s.AddressList != "" {
+		*buf = appendQuoted(*buf, "address-list", s.AddressList, escape)
+	}
+
+	// write "cname"
+	if s.CNAME != "" {
+		*buf = appendQuoted(*buf, "cname", s.CNAME, escape)
+	}
 
 	// write "comment"
 	if s.Comment != "" {
-		*buf = append(*buf, ` comment="`+s.Comment+`"`...)
+		*buf = appendQuoted(*buf, "comment", s.Comment, escape)
 	}
 
 	// write "disabled"
-	*buf = append(*buf, ` disabled=`+s.boolToString(s.Disabled)...)
+	*buf = append(*buf, ` disabled=`+boolToString(s.Disabled)...)
+
+	// write "forward-to"
+	if s.ForwardTo != "" {
+		*buf = append(*buf, ` forward-to=`+s.ForwardTo...)
+	}
+
+	// write "match-subdomain"
+	if s.MatchSubdomain {
+		*buf = append(*buf, ` match-subdomain=yes`...)
+	}
 
 	// write "name"
 	if s.Name != "" {
-		*buf = append(*buf, ` name="`+s.Name+`"`...)
+		*buf = appendQuoted(*buf, "name", s.Name, escape)
 	}
 
 	// write "regexp"
 	if s.Regexp != "" {
-		*buf = append(*buf, ` regexp="`+s.Regexp+`"`...)
+		*buf = appendQuoted(*buf, "regexp", s.Regexp, escape)
+	}
+
+	// write "text"
+	if s.Text != "" {
+		*buf = appendQuoted(*buf, "text", s.Text, escape)
 	}
 
 	// write "ttl"
 	if s.TTL != "" {
-		*buf = append(*buf, ` ttl="`+s.TTL+`"`...)
+		*buf = appendQuoted(*buf, "ttl", s.TTL, escape)
+	}
+
+	// write "type"
+	if s.Type != "" {
+		*buf = append(*buf, ` type=`+string(s.Type)...)
 	}
 
 	// write entry Postfix
@@ -65,13 +155,53 @@ func (s *DNSStaticEntry) format(buf *[]byte, prefix, postfix string) error {
 		*buf = append(*buf, " "+postfix...)
 	}
 
+	// remove the leading space, if prefix is empty
+	if len(prefix) == 0 {
+		*buf = append((*buf)[:start], (*buf)[start+1:]...)
+	}
+
 	return nil
 }
 
-func (DNSStaticEntry) boolToString(b bool) string {
+func boolToString(b bool) string {
 	if b {
 		return "yes"
 	}
 
 	return "no"
 }
+
+func (s *DNSStaticEntry) appendFormatted(buf []byte, prefix, postfix string, escape bool) ([]byte, error) {
+	err := s.format(&buf, prefix, postfix, escape)
+
+	return buf, err
+}
+
+// appendGuard appends the beginning of the condition, that checks the entry existence (by the name or regular
+// expression): `:if ([:len [find name="..."]] = 0) do={`. Closing brace must be appended after the entry.
+func (s *DNSStaticEntry) appendGuard(buf []byte, escape bool) []byte {
+	buf = append(buf, `:if ([:len [find`...)
+
+	if s.Name != "" {
+		buf = appendQuoted(buf, "name", s.Name, escape)
+	} else {
+		buf = appendQuoted(buf, "regexp", s.Regexp, escape)
+	}
+
+	return append(buf, `]] = 0) do={`...)
+}
+
+// appendQuoted appends the ` key="value"` pair into the buffer.
+func appendQuoted(buf []byte, key, value string, escape bool) []byte {
+	buf = append(buf, ' ')
+	buf = append(buf, key...)
+	buf = append(buf, '=', '"')
+
+	if escape {
+		buf = appendEscaped(buf, value)
+	} else {
+		buf = append(buf, value...)
+	}
+
+	return append(buf, '"')
+}
@@ -67,6 +67,88 @@ func TestDNSStaticEntry_Format(t *testing.T) {
 			},
 			wantString: `address=127.0.0.1 comment="Any\ text" disabled=no name="www.example\.com" regexp=".*\.example\.com" ttl="1\d"`, //nolint:lll
 		},
+		{
+			name: "nxdomain",
+			giveEntry: DNSStaticEntry{
+				Comment:        "foo",
+				MatchSubdomain: true,
+				Name:           "example.com",
+				Type:           TypeNXDOMAIN,
+			},
+			wantString: `comment="foo" disabled=no match-subdomain=yes name="example.com" type=NXDOMAIN`,
+		},
+		{
+			name: "nxdomain with prefix",
+			giveEntry: DNSStaticEntry{
+				Name: "example.com",
+				Type: TypeNXDOMAIN,
+			},
+			givePrefix: "add",
+			wantString: `add disabled=no name="example.com" type=NXDOMAIN`,
+		},
+		{
+			name: "forwarding",
+			giveEntry: DNSStaticEntry{
+				ForwardTo: "1.1.1.1",
+				Regexp:    `.*\.example\.com`,
+				Type:      TypeFWD,
+			},
+			wantString: `disabled=no forward-to=1.1.1.1 regexp=".*\.example\.com" type=FWD`,
+		},
+		{
+			name: "cname",
+			giveEntry: DNSStaticEntry{
+				CNAME: "bar.com",
+				Name:  "foo.com",
+				Type:  TypeCNAME,
+			},
+			wantString: `cname="bar.com" disabled=no name="foo.com" type=CNAME`,
+		},
+		{
+			name: "ipv6 address with address list",
+			giveEntry: DNSStaticEntry{
+				Address:     "::1",
+				AddressList: "blocked",
+				Name:        "foo.com",
+				TTL:         "1h",
+				Type:        TypeAAAA,
+			},
+			givePostfix: "bar",
+			wantString:  `address=::1 address-list="blocked" disabled=no name="foo.com" ttl="1h" type=AAAA bar`,
+		},
+		{
+			name: "txt",
+			giveEntry: DNSStaticEntry{
+				Name: "foo.com",
+				Text: "v=spf1 -all",
+				Type: TypeTXT,
+			},
+			wantString: `disabled=no name="foo.com" text="v=spf1 -all" type=TXT`,
+		},
+		{
+			name:       "cname without cname value",
+			giveEntry:  DNSStaticEntry{Name: "foo.com", Type: TypeCNAME},
+			wantString: "",
+			wantError:  ErrEmptyFields,
+		},
+		{
+			name:       "forwarding without server",
+			giveEntry:  DNSStaticEntry{Name: "foo.com", Type: TypeFWD},
+			wantString: "",
+			wantError:  ErrEmptyFields,
+		},
+		{
+			name:       "txt without text",
+			giveEntry:  DNSStaticEntry{Name: "foo.com", Type: TypeTXT},
+			wantString: "",
+			wantError:  ErrEmptyFields,
+		},
+		{
+			name:       "unsupported type",
+			giveEntry:  DNSStaticEntry{Address: "0.0.0.0", Name: "foo.com", Type: "MX"},
+			wantString: "",
+			wantError:  ErrUnsupportedType,
+		},
 		{
 			name:       "empty",
 			giveEntry:  DNSStaticEntry{},
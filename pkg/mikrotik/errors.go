@@ -11,6 +11,12 @@ func (err Error) Error() string {
 	case ErrEmptyFields:
 		return "required fields does not filled"
 
+	case ErrUnsupportedType:
+		return "unsupported entry type"
+
+	case ErrSyntax:
+		return "syntax error"
+
 	default:
 		return unknownError
 	}
@@ -18,3 +24,9 @@ func (err Error) Error() string {
 
 // ErrEmptyFields means required fields does not filled.
 const ErrEmptyFields Error = 1
+
+// ErrUnsupportedType means unsupported static DNS entry type.
+const ErrUnsupportedType Error = 2
+
+// ErrSyntax means malformed RouterOS script (export) line or escape sequence.
+const ErrSyntax Error = 3
@@ -13,6 +13,16 @@ func TestConstErr_Error(t *testing.T) {
 			giveConst:  ErrEmptyFields,
 			wantString: "required fields does not filled",
 		},
+		{
+			name:       "ErrUnsupportedType",
+			giveConst:  ErrUnsupportedType,
+			wantString: "unsupported entry type",
+		},
+		{
+			name:       "ErrSyntax",
+			giveConst:  ErrSyntax,
+			wantString: "syntax error",
+		},
 		{
 			name:       "0",
 			giveConst:  Error(0),
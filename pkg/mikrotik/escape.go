@@ -0,0 +1,129 @@
+package mikrotik
+
+import (
+	"fmt"
+	"strings"
+)
+
+const hexDigits = "0123456789ABCDEF"
+
+// Escape returns the string, escaped using RouterOS script string escaping rules (`"`, `\`, `$` and `?` are
+// prefixed with the backslash, control characters are written as `\XX`, where XX is a hex code). The same string
+// will be returned (without allocation) if nothing needs to be escaped.
+func Escape(s string) string {
+	if !needsEscaping(s) {
+		return s
+	}
+
+	return string(appendEscaped(make([]byte, 0, len(s)+8), s))
+}
+
+// needsEscaping checks the string for the characters, that must be escaped inside the quoted RouterOS string.
+func needsEscaping(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '"' || c == '\\' || c == '$' || c == '?' || c < ' ' || c == 0x7f {
+			return true
+		}
+	}
+
+	return false
+}
+
+// appendEscaped appends escaped string into the buffer. The string is appended as-is, if nothing needs to be escaped.
+func appendEscaped(buf []byte, s string) []byte {
+	if !needsEscaping(s) {
+		return append(buf, s...)
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"', c == '\\', c == '$', c == '?':
+			buf = append(buf, '\\', c)
+
+		case c < ' ', c == 0x7f:
+			buf = append(buf, '\\', hexDigits[c>>4], hexDigits[c&0x0f])
+
+		default:
+			buf = append(buf, c)
+		}
+	}
+
+	return buf
+}
+
+// Unescape returns the string with RouterOS script escape sequences (`\"`, `\\`, `\$`, `\?`, `\_`, `\a`, `\b`, `\f`,
+// `\n`, `\r`, `\t`, `\v` and `\XX`, where XX is an uppercase hex code) replaced. It is the inverse of Escape. The
+// same string will be returned (without allocation) if it does not contain escape sequences.
+func Unescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var buf = make([]byte, 0, len(s))
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			buf = append(buf, s[i])
+
+			continue
+		}
+
+		if i++; i >= len(s) {
+			return "", fmt.Errorf("%w: unterminated escape sequence", ErrSyntax)
+		}
+
+		if c, ok := unescapedChar(s[i]); ok {
+			buf = append(buf, c)
+
+			continue
+		}
+
+		hi, lo := hexValue(s[i]), byte(0xff)
+		if i+1 < len(s) {
+			lo = hexValue(s[i+1])
+		}
+
+		if hi == 0xff || lo == 0xff {
+			return "", fmt.Errorf("%w: wrong escape sequence in [%s]", ErrSyntax, s)
+		}
+
+		buf, i = append(buf, hi<<4|lo), i+1
+	}
+
+	return string(buf), nil
+}
+
+// unescapedChar returns the character for the single-character escape sequence (eg.: `n` for `\n`).
+func unescapedChar(c byte) (byte, bool) {
+	switch c {
+	case '"', '\\', '$', '?':
+		return c, true
+	case '_':
+		return ' ', true
+	case 'a':
+		return '\a', true
+	case 'b':
+		return '\b', true
+	case 'f':
+		return '\f', true
+	case 'n':
+		return '\n', true
+	case 'r':
+		return '\r', true
+	case 't':
+		return '\t', true
+	case 'v':
+		return '\v', true
+	}
+
+	return 0, false
+}
+
+// hexValue returns the uppercase hex digit value (0xff for the wrong digit).
+func hexValue(c byte) byte {
+	if idx := strings.IndexByte(hexDigits, c); idx >= 0 {
+		return byte(idx)
+	}
+
+	return 0xff
+}
@@ -0,0 +1,80 @@
+package mikrotik
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscape(t *testing.T) {
+	for name, tt := range map[string]struct {
+		give string
+		want string
+	}{
+		"empty":             {give: "", want: ""},
+		"nothing to escape": {give: "www.example.com", want: "www.example.com"},
+		"unicode":           {give: "тест.рф", want: "тест.рф"},
+		"double quotes":     {give: `foo "bar"`, want: `foo \"bar\"`},
+		"backslash":         {give: `foo\bar`, want: `foo\\bar`},
+		"variable":          {give: "$foo", want: `\$foo`},
+		"question mark":     {give: "foo?", want: `foo\?`},
+		"control chars":     {give: "foo\nbar\r\tbaz\x00\x1f\x7f", want: `foo\0Abar\0D\09baz\00\1F\7F`},
+		"regexp":            {give: `.*\.example\.com$`, want: `.*\\.example\\.com\$`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Escape(tt.give))
+		})
+	}
+}
+
+func TestAppendEscapedWithoutAllocations(t *testing.T) {
+	var buf = make([]byte, 0, 64)
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		buf = appendEscaped(buf[:0], "www.example.com")
+	}))
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_ = Escape("www.example.com")
+	}))
+}
+
+func TestUnescape(t *testing.T) {
+	for name, tt := range map[string]struct {
+		give    string
+		want    string
+		wantErr bool
+	}{
+		"empty":             {give: "", want: ""},
+		"nothing to escape": {give: "www.example.com", want: "www.example.com"},
+		"double quotes":     {give: `foo \"bar\"`, want: `foo "bar"`},
+		"backslash":         {give: `foo\\bar`, want: `foo\bar`},
+		"variable":          {give: `\$foo\?`, want: "$foo?"},
+		"hex codes":         {give: `foo\0Abar\0D\09baz\00\1F\7F`, want: "foo\nbar\r\tbaz\x00\x1f\x7f"},
+		"letter escapes":    {give: `\a\b\f\n\r\t\v\_`, want: "\a\b\f\n\r\t\v "},
+		"lowercase hex":     {give: `\0a`, wantErr: true},
+		"short hex":         {give: `foo\1`, wantErr: true},
+		"unknown escape":    {give: `\x`, wantErr: true},
+		"trailing slash":    {give: `foo\`, wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := Unescape(tt.give)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrSyntax)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, s := range []string{"", `foo "bar"`, "$\\?\x00\x7f\n", `.*\.example\.com$`} {
+		got, err := Unescape(Escape(s))
+
+		assert.NoError(t, err)
+		assert.Equal(t, s, got)
+	}
+}
@@ -0,0 +1,88 @@
+package mikrotik
+
+import "io"
+
+// FirewallAddressListEntry is firewall address list entry for RouterOS usage. RouterOS resolves the domain names in
+// the address lists, so the hostnames can be used for the firewall-based blocking.
+type FirewallAddressListEntry struct {
+	Address  string // IP address, subnet or domain name (eg.: www.example.com)
+	Comment  string // Short description of the item (eg.: Any text)
+	Disabled bool   // Defines whether item is ignored or used (eg.: yes,no)
+	List     string // Address list name (eg.: blocked)
+	Timeout  string // Time after the entry will be removed, the entry is dynamic when set (eg.: 1d)
+}
+
+// FirewallAddressList is firewall address list entries set.
+type FirewallAddressList []FirewallAddressListEntry
+
+// Render mikrotik firewall address list entries and write them into some writer. Returned values is count of wrote
+// bytes and error, if something goes wrong.
+func (l FirewallAddressList) Render(to io.Writer, opts ...RenderingOptions) (int, error) {
+	return render(to, l, opts)
+}
+
+// Format entry as a text in RouterOS script format.
+// Important: keep im mind that any unexpected characters will be formatted as-is (without escaping or filtering), use
+// FirewallAddressList.Render with the RenderingOptions.Escape option for the escaping.
+func (e *FirewallAddressListEntry) Format(prefix, postfix string) ([]byte, error) {
+	const overSize = 64 // pre-allocation reserve
+
+	return e.appendFormatted(
+		make([]byte, 0, len(e.Address)+len(e.Comment)+len(e.List)+len(e.Timeout)+overSize),
+		prefix, postfix, false,
+	)
+}
+
+// appendFormatted documentation: <https://help.mikrotik.com/docs/display/ROS/Address-lists>
+// Important: empty values will NOT be printed.
+func (e *FirewallAddressListEntry) appendFormatted(buf []byte, prefix, postfix string, escape bool) ([]byte, error) {
+	if e.Address == "" || e.List == "" {
+		return buf, ErrEmptyFields
+	}
+
+	var start = len(buf)
+
+	// write prefix (every next field starts with the space)
+	buf = append(buf, prefix...)
+
+	// write "address"
+	buf = appendQuoted(buf, "address", e.Address, escape)
+
+	// write "comment"
+	if e.Comment != "" {
+		buf = appendQuoted(buf, "comment", e.Comment, escape)
+	}
+
+	// write "disabled"
+	buf = append(buf, ` disabled=`+boolToString(e.Disabled)...)
+
+	// write "list"
+	buf = appendQuoted(buf, "list", e.List, escape)
+
+	// write "timeout"
+	if e.Timeout != "" {
+		buf = append(buf, ` timeout=`+e.Timeout...)
+	}
+
+	// write entry Postfix
+	if len(postfix) > 0 {
+		buf = append(buf, " "+postfix...)
+	}
+
+	// remove the leading space, if prefix is empty
+	if len(prefix) == 0 {
+		buf = append(buf[:start], buf[start+1:]...)
+	}
+
+	return buf, nil
+}
+
+// appendGuard appends the beginning of the condition, that checks the entry existence (by the list name and
+// address): `:if ([:len [find list="..." address="..."]] = 0) do={`. Closing brace must be appended after the entry.
+func (e *FirewallAddressListEntry) appendGuard(buf []byte, escape bool) []byte {
+	buf = append(buf, `:if ([:len [find`...)
+	buf = appendQuoted(buf, "list", e.List, escape)
+	buf = appendQuoted(buf, "address", e.Address, escape)
+
+	return append(buf, `]] = 0) do={`...)
+}
@@ -0,0 +1,96 @@
+package mikrotik
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func BenchmarkFirewallAddressList_Render(b *testing.B) {
+	b.ReportAllocs()
+
+	data := make(FirewallAddressList, 0, 1000)
+
+	for range 1000 {
+		data = append(data, FirewallAddressListEntry{Address: "www.example.com", Comment: "Any text", List: "blocked"})
+	}
+
+	dest := bytes.NewBuffer([]byte{})
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		dest.Reset()
+
+		_, _ = data.Render(dest, RenderingOptions{Prefix: "add", Escape: true})
+	}
+}
+
+func TestFirewallAddressList_Render(t *testing.T) {
+	for name, tt := range map[string]struct {
+		giveEntries FirewallAddressList
+		giveOptions RenderingOptions
+		wantResult  string
+	}{
+		"empty input": {
+			giveEntries: FirewallAddressList{{}},
+			wantResult:  "",
+		},
+		"minimal": {
+			giveEntries: FirewallAddressList{{Address: "example.com", List: "blocked"}},
+			wantResult:  `address="example.com" disabled=no list="blocked"`,
+		},
+		"all fields": {
+			giveEntries: FirewallAddressList{{
+				Address:  "10.0.0.0/8",
+				Comment:  "foo comment",
+				Disabled: true,
+				List:     "blocked",
+				Timeout:  "1d",
+			}},
+			giveOptions: RenderingOptions{Prefix: "add", Postfix: "bar"},
+			wantResult:  `add address="10.0.0.0/8" comment="foo comment" disabled=yes list="blocked" timeout=1d bar`,
+		},
+		"two entries (one without list)": {
+			giveEntries: FirewallAddressList{
+				{Address: "foo.com"},
+				{Address: "bar.com", List: "blocked"},
+				{Address: "baz.com", List: "blocked"},
+			},
+			giveOptions: RenderingOptions{Prefix: "add"},
+			wantResult: `add address="bar.com" disabled=no list="blocked"` + "\n" +
+				`add address="baz.com" disabled=no list="blocked"`,
+		},
+		"with escaping and guards": {
+			giveEntries: FirewallAddressList{{Address: "foo.com", Comment: `"foo" $bar`, List: `my "list"`}},
+			giveOptions: RenderingOptions{Prefix: "add", Escape: true, Guard: true},
+			wantResult: `:if ([:len [find list="my \"list\"" address="foo.com"]] = 0) do={add address="foo.com" ` +
+				`comment="\"foo\" \$bar" disabled=no list="my \"list\""}`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			l, err := tt.giveEntries.Render(&buf, tt.giveOptions)
+
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.wantResult), l)
+			assert.Equal(t, tt.wantResult, buf.String())
+		})
+	}
+}
+
+func TestFirewallAddressListEntry_Format(t *testing.T) {
+	var entry = FirewallAddressListEntry{Address: "example.com", Comment: `"foo"`, List: "blocked", Timeout: "1h"}
+
+	res, err := entry.Format("add", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `add address="example.com" comment=""foo"" disabled=no list="blocked" timeout=1h`, string(res))
+
+	res, err = (&FirewallAddressListEntry{Address: "example.com"}).Format("", "")
+
+	assert.ErrorIs(t, err, ErrEmptyFields)
+	assert.Empty(t, res)
+}
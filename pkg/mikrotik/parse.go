@@ -0,0 +1,360 @@
+package mikrotik
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	dnsStaticMenu = "/ip dns static"
+
+	plainPrintUnsupported = "plain `print` output is not supported (use `export` or `print terse`)"
+)
+
+// ParseDNSStaticEntries reads static DNS entries from the RouterOS `/ip dns static export` (or `/export`) output.
+// Only entries of the `/ip dns static` menu are read (lines without any menu are treated as its entries), comments
+// and empty lines are skipped. `print terse` and `print detail` outputs (with the item numbers and flags) are
+// supported too: `;;; text` comment (on the separate line or after the item number) is used as the comment of the
+// following item, and the item properties can be printed on the several lines (until the empty line or the next
+// item). The plain (table) `print` output is not supported (table columns can be truncated) - ErrSyntax is returned.
+//
+// Line continuations (`\` at the end of line), quoted values (with the RouterOS escape sequences) and unquoted
+// values are handled, so it is the inverse of the DNSStaticEntries.Render (with the "add" prefix). Unknown entry
+// properties are ignored. ErrSyntax (wrapped with the line number) will be returned for the malformed lines.
+func ParseDNSStaticEntries(r io.Reader) (DNSStaticEntries, error) {
+	var (
+		scanner = bufio.NewScanner(r)
+		p       = dnsStaticParser{entries: make(DNSStaticEntries, 0, 32)}
+		lineNum int
+		inMenu  = true // entries without menu are treated as `/ip dns static` entries
+	)
+
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+
+	for {
+		line, start, ok := nextLogicalLine(scanner, &lineNum)
+		if !ok {
+			break
+		}
+
+		if strings.HasPrefix(line, "/") { // menu (section) header, eg.: `/ip dns static`
+			if err := p.flush(); err != nil {
+				return nil, err
+			}
+
+			if inMenu = strings.HasPrefix(line+" ", dnsStaticMenu+" "); !inMenu {
+				continue
+			}
+
+			line = strings.TrimSpace(line[len(dnsStaticMenu):])
+		}
+
+		if !inMenu || (line != "" && line[0] == '#') || strings.HasPrefix(line, "Flags:") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "Columns:") {
+			return nil, fmt.Errorf("line %d: %w: %s", start, ErrSyntax, plainPrintUnsupported)
+		}
+
+		if err := p.line(line, start); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := p.flush(); err != nil {
+		return nil, err
+	}
+
+	return p.entries, nil
+}
+
+// dnsStaticParser collects the `print` output items (item properties can be printed on the several lines) and parses
+// them into the entries.
+type dnsStaticParser struct {
+	entries DNSStaticEntries
+
+	item     string // current item (the item number, flags and properties)
+	itemLine int    // current item first line number
+	numbered bool   // current item starts with the item number (its properties can be continued on the next lines)
+
+	comment    string // `;;;` comment for the next item
+	hasComment bool
+}
+
+// line handles the logical (trimmed) line with passed number.
+func (p *dnsStaticParser) line(line string, num int) error {
+	switch {
+	case line == "": // items are separated by the empty lines
+		return p.flush()
+
+	case strings.HasPrefix(line, ";;;"): // comment for the following item
+		if err := p.flush(); err != nil {
+			return err
+		}
+
+		p.comment, p.hasComment = strings.TrimSpace(line[3:]), true
+
+		return nil
+
+	case p.numbered && !startsWithWord(line): // next properties of the current item
+		p.item += " " + line
+
+		return nil
+	}
+
+	if err := p.flush(); err != nil {
+		return err
+	}
+
+	if rest, comment, ok := splitItemComment(line); ok { // `print detail` item, eg.: ` 0 X ;;; comment`
+		line, p.comment, p.hasComment = rest, comment, true
+	}
+
+	var first, _ = nextWord(line)
+
+	p.item, p.itemLine, p.numbered = line, num, first != "" && strings.Trim(first, "0123456789") == ""
+
+	return nil
+}
+
+// flush parses the current item (if any).
+func (p *dnsStaticParser) flush() error {
+	if p.item == "" {
+		return nil
+	}
+
+	entry, err := parseEntry(p.item)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", p.itemLine, err)
+	}
+
+	if p.hasComment {
+		entry.Comment = p.comment
+	}
+
+	p.entries = append(p.entries, entry)
+	p.item, p.numbered, p.comment, p.hasComment = "", false, "", false
+
+	return nil
+}
+
+// startsWithWord checks that the line starts with the word without the value (the command, item number or flags),
+// not with the `key=value` property.
+func startsWithWord(line string) bool {
+	var end = strings.IndexAny(line, " \t=")
+
+	return end < 0 || line[end] != '='
+}
+
+// splitItemComment splits the `print detail` item line with the `;;;` comment after the item number and flags, eg.:
+// ` 0 X ;;; comment`. The item line without the comment and the comment are returned.
+func splitItemComment(line string) (string, string, bool) {
+	var (
+		rest = line
+		word string
+	)
+
+	for rest != "" {
+		if strings.HasPrefix(rest, ";;;") {
+			return strings.TrimSpace(line[:len(line)-len(rest)]), strings.TrimSpace(rest[3:]), true
+		}
+
+		if word, rest = nextWord(rest); word == "add" || !isEntryPrefix(word, new(DNSStaticEntry)) {
+			break
+		}
+	}
+
+	return line, "", false
+}
+
+// nextLogicalLine reads the next line, joining the continued (ending with the odd count of backslashes) lines. The
+// leading whitespaces of the continued lines are removed. Trimmed line and its first line number are returned.
+func nextLogicalLine(scanner *bufio.Scanner, lineNum *int) (string, int, bool) {
+	var (
+		line  strings.Builder
+		start = *lineNum + 1
+		read  bool
+	)
+
+	for scanner.Scan() {
+		*lineNum, read = *lineNum+1, true
+
+		var part = strings.TrimRight(scanner.Text(), "\r")
+
+		if line.Len() > 0 {
+			part = strings.TrimLeft(part, " \t")
+		}
+
+		if trimmed := strings.TrimRight(part, "\\"); (len(part)-len(trimmed))%2 == 1 {
+			line.WriteString(part[:len(part)-1])
+
+			continue
+		}
+
+		line.WriteString(part)
+
+		break
+	}
+
+	return strings.TrimSpace(line.String()), start, read
+}
+
+// parseEntry parses the entry line, eg.: `add address=0.0.0.0 comment="foo" name=example.com`.
+func parseEntry(line string) (DNSStaticEntry, error) {
+	var (
+		entry      DNSStaticEntry
+		properties bool // at least one property was read
+		item       bool // the `print` item number was read
+	)
+
+	for line != "" {
+		var end = strings.IndexAny(line, " \t=")
+
+		if end < 0 || line[end] != '=' { // a word without the value
+			var word string
+
+			word, line = nextWord(line)
+
+			switch {
+			case !properties && isEntryPrefix(word, &entry):
+				item = item || strings.Trim(word, "0123456789") == ""
+
+				continue
+			case !properties && item: // plain `print` output - the item number is followed by the columns values
+				return entry, fmt.Errorf("%w: %s", ErrSyntax, plainPrintUnsupported)
+			}
+
+			return entry, fmt.Errorf("%w: unexpected word [%s]", ErrSyntax, word)
+		}
+
+		var (
+			key, value = line[:end], line[end+1:]
+			err        error
+		)
+
+		if strings.HasPrefix(value, `"`) { // quoted value can contain spaces
+			if value, line, err = quotedValue(value); err != nil {
+				return entry, err
+			}
+		} else {
+			value, line = nextWord(value)
+		}
+
+		if value, err = Unescape(value); err != nil {
+			return entry, err
+		}
+
+		if err = entry.set(key, value); err != nil {
+			return entry, err
+		}
+
+		properties = true
+	}
+
+	if !properties {
+		return entry, fmt.Errorf("%w: entry without properties", ErrSyntax)
+	}
+
+	return entry, nil
+}
+
+// nextWord returns the word until the whitespace and the rest of the line (without leading whitespaces).
+func nextWord(line string) (string, string) {
+	if idx := strings.IndexAny(line, " \t"); idx >= 0 {
+		return line[:idx], strings.TrimLeft(line[idx:], " \t")
+	}
+
+	return line, ""
+}
+
+// quotedValue reads the quoted value (s must start with the quote) and returns it (without quotes, but still
+// escaped) and the rest of the line (without leading whitespaces).
+func quotedValue(s string) (string, string, error) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++ // skip the escaped character
+
+		case '"':
+			if rest := s[i+1:]; rest == "" || rest[0] == ' ' || rest[0] == '\t' {
+				return s[1:i], strings.TrimLeft(rest, " \t"), nil
+			}
+
+			return "", "", fmt.Errorf("%w: unexpected characters after the quoted value", ErrSyntax)
+		}
+	}
+
+	return "", "", fmt.Errorf("%w: unterminated quoted value", ErrSyntax)
+}
+
+// isEntryPrefix checks the words before the entry properties: the `add` command, `print` item numbers and flags
+// (`X` flag means disabled entry).
+func isEntryPrefix(word string, entry *DNSStaticEntry) bool {
+	if word == "add" {
+		return true
+	}
+
+	var digits, flags = true, true
+
+	for i := 0; i < len(word); i++ {
+		digits = digits && word[i] >= '0' && word[i] <= '9'
+		flags = flags && word[i] >= 'A' && word[i] <= 'Z'
+	}
+
+	if flags && strings.Contains(word, "X") {
+		entry.Disabled = true
+	}
+
+	return digits || flags
+}
+
+// set the entry property by the RouterOS property name. Unknown properties are ignored.
+func (s *DNSStaticEntry) set(key, value string) error { //nolint:gocyclo
+	switch key {
+	case "address":
+		s.Address = value
+	case "address-list":
+		s.AddressList = value
+	case "cname":
+		s.CNAME = value
+	case "comment":
+		s.Comment = value
+	case "forward-to":
+		s.ForwardTo = value
+	case "name":
+		s.Name = value
+	case "regexp":
+		s.Regexp = value
+	case "text":
+		s.Text = value
+	case "ttl":
+		s.TTL = value
+	case "type":
+		s.Type = EntryType(strings.ToUpper(value))
+	case "disabled", "match-subdomain":
+		var flag bool
+
+		switch value {
+		case "yes", "true":
+			flag = true
+		case "no", "false":
+		default:
+			return fmt.Errorf("%w: wrong [%s] value [%s]", ErrSyntax, key, value)
+		}
+
+		if key == "disabled" {
+			s.Disabled = flag
+		} else {
+			s.MatchSubdomain = flag
+		}
+	}
+
+	return nil
+}
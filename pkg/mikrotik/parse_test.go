@@ -0,0 +1,147 @@
+package mikrotik
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDNSStaticEntries(t *testing.T) {
+	entries, err := ParseDNSStaticEntries(strings.NewReader(`# oct/17/2026 10:00:00 by RouterOS 7.12
+# software id = ABCD-1234
+#
+/interface bridge
+add name=bridge1
+/ip dns static
+add address=0.0.0.0 comment="foo bar" name=a.example.com
+add address=0.0.0.0 comment="very long comment, that is \
+    continued on the next line" disabled=yes name=b.example.com \
+    ttl=1d
+add cname=example.com name=c.example.com type=CNAME
+add match-subdomain=yes name=d.example.com type=NXDOMAIN
+add address=::1 name=e.example.com regexp="^.*\\.example\\.com\$" type=AAAA
+add forward-to=1.1.1.1 name=f.example.com type=FWD
+add name=g.example.com text="v=spf1 \"-all\"" type=TXT
+/ip firewall address-list
+add address=1.2.3.4 list=blocked
+/ip dns static add address=127.0.0.1 name=h.example.com
+`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, DNSStaticEntries{
+		{Address: "0.0.0.0", Comment: "foo bar", Name: "a.example.com"},
+		{
+			Address:  "0.0.0.0",
+			Comment:  "very long comment, that is continued on the next line",
+			Disabled: true,
+			Name:     "b.example.com",
+			TTL:      "1d",
+		},
+		{CNAME: "example.com", Name: "c.example.com", Type: TypeCNAME},
+		{MatchSubdomain: true, Name: "d.example.com", Type: TypeNXDOMAIN},
+		{Address: "::1", Name: "e.example.com", Regexp: `^.*\.example\.com$`, Type: TypeAAAA},
+		{ForwardTo: "1.1.1.1", Name: "f.example.com", Type: TypeFWD},
+		{Name: "g.example.com", Text: `v=spf1 "-all"`, Type: TypeTXT},
+		{Address: "127.0.0.1", Name: "h.example.com"},
+	}, entries)
+}
+
+func TestParseDNSStaticEntriesPrintTerse(t *testing.T) {
+	entries, err := ParseDNSStaticEntries(strings.NewReader("Flags: X - DISABLED\r\n" +
+		" 0   name=a.com address=0.0.0.0 ttl=1d\r\n" +
+		" 1 X name=b.com address=0.0.0.0 ttl=1d\r\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, DNSStaticEntries{
+		{Address: "0.0.0.0", Name: "a.com", TTL: "1d"},
+		{Address: "0.0.0.0", Disabled: true, Name: "b.com", TTL: "1d"},
+	}, entries)
+}
+
+func TestParseDNSStaticEntriesPrintDetail(t *testing.T) {
+	entries, err := ParseDNSStaticEntries(strings.NewReader("Flags: D - DYNAMIC; X - DISABLED\r\n" +
+		" 0    ;;; ADBlock: [ads]\r\n" +
+		"      name=\"a.com\" type=A address=0.0.0.0\r\n" +
+		"      ttl=1d\r\n" +
+		"\r\n" +
+		" 1 X  ;;; ADBlock\r\n" +
+		"      name=\"b.com\" address=0.0.0.0 ttl=1d\r\n" +
+		"\r\n" +
+		" 2    name=\"c.com\" address=0.0.0.0 ttl=1d\r\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, DNSStaticEntries{
+		{Address: "0.0.0.0", Comment: "ADBlock: [ads]", Name: "a.com", TTL: "1d", Type: TypeA},
+		{Address: "0.0.0.0", Comment: "ADBlock", Disabled: true, Name: "b.com", TTL: "1d"},
+		{Address: "0.0.0.0", Name: "c.com", TTL: "1d"},
+	}, entries)
+}
+
+func TestParseDNSStaticEntriesPrintComment(t *testing.T) {
+	entries, err := ParseDNSStaticEntries(strings.NewReader("Flags: X - DISABLED\n" +
+		" ;;; ADBlock\n" +
+		" 0   name=a.com address=0.0.0.0\n" +
+		" 1   name=b.com address=0.0.0.0\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, DNSStaticEntries{
+		{Address: "0.0.0.0", Comment: "ADBlock", Name: "a.com"},
+		{Address: "0.0.0.0", Name: "b.com"}, // the comment belongs to the following item only
+	}, entries)
+}
+
+func TestParseDNSStaticEntriesErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		give    string
+		wantErr string
+	}{
+		"unknown command":  {give: "remove 0", wantErr: "line 1: syntax error: unexpected word [remove]"},
+		"word after props": {give: "add name=a.com foo", wantErr: "line 1: syntax error: unexpected word [foo]"},
+		"no properties":    {give: "\nadd", wantErr: "line 2: syntax error: entry without properties"},
+		"unterminated":     {give: `add name="a.com`, wantErr: "line 1: syntax error: unterminated quoted value"},
+		"after quoted":     {give: `add name="a"b`, wantErr: "line 1: syntax error: unexpected characters after"},
+		"wrong escape":     {give: `add name="a\x"`, wantErr: "line 1: syntax error: wrong escape sequence"},
+		"wrong bool":       {give: "add name=a.com disabled=maybe", wantErr: "line 1: syntax error: wrong [disabled]"},
+		"continued line":   {give: "add name=a.com \\\n  foo\nadd bar", wantErr: "line 1: syntax error: unexpected"},
+		"plain print": {
+			give:    "Flags: X - DISABLED\n #   NAME    ADDRESS  TTL\n 0   a.com   0.0.0.0  1d",
+			wantErr: "line 3: syntax error: plain `print` output is not supported",
+		},
+		"plain print v7": {
+			give:    "Columns: NAME, ADDRESS, TTL\n#   NAME   ADDRESS  TTL\n0   a.com  0.0.0.0  1d",
+			wantErr: "line 1: syntax error: plain `print` output is not supported",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			entries, err := ParseDNSStaticEntries(strings.NewReader(tt.give))
+
+			assert.ErrorIs(t, err, ErrSyntax)
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Nil(t, entries)
+		})
+	}
+}
+
+func TestParseDNSStaticEntriesRoundTrip(t *testing.T) {
+	var entries = DNSStaticEntries{
+		{Address: "0.0.0.0", Comment: `foo "bar" $baz?`, Name: "a.example.com", TTL: "1d"},
+		{Address: "0.0.0.0", Comment: "tabs\tand\nnew lines", Disabled: true, Name: "b.example.com"},
+		{AddressList: "blocked", CNAME: "example.com", Name: "c.example.com", Type: TypeCNAME},
+		{MatchSubdomain: true, Name: "d.example.com", Type: TypeNXDOMAIN},
+		{Address: "::1", Regexp: `^(a|b)\.example\.com$`, Type: TypeAAAA},
+		{ForwardTo: "1.1.1.1", Name: "f.example.com", Type: TypeFWD},
+		{Name: "g.example.com", Text: `v=spf1 \ -all`, Type: TypeTXT},
+	}
+
+	var buf bytes.Buffer
+
+	_, err := entries.Render(&buf, RenderingOptions{Prefix: "add", Escape: true})
+	assert.NoError(t, err)
+
+	parsed, err := ParseDNSStaticEntries(strings.NewReader("/ip dns static\n" + buf.String()))
+
+	assert.NoError(t, err)
+	assert.Equal(t, entries, parsed)
+}
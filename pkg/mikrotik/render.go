@@ -0,0 +1,72 @@
+package mikrotik
+
+import "io"
+
+const renderBufferCapacity = 128
+
+// RenderingOptions describes options for rendering.
+type RenderingOptions struct {
+	Prefix, Postfix string
+	Escape          bool // escape quoted values using RouterOS string escaping rules (eg.: `"` becomes `\"`)
+	Guard           bool // wrap entries into `:if ([:len [find ...]] = 0) do={...}` (skip existing entries)
+}
+
+// formatter is an entry, that can be rendered in RouterOS script format.
+type formatter interface {
+	// appendFormatted appends the entry into the buffer. Error will be returned if required fields are not filled.
+	appendFormatted(buf []byte, prefix, postfix string, escape bool) ([]byte, error)
+
+	// appendGuard appends the beginning of the condition, that checks the entry existence.
+	appendGuard(buf []byte, escape bool) []byte
+}
+
+// render entries and write them (separated by the line breaks) into some writer. Invalid entries are skipped.
+func render[T any, PT interface {
+	*T
+	formatter
+}](to io.Writer, entries []T, opts []RenderingOptions) (int, error) {
+	var (
+		buf     = make([]byte, 0, renderBufferCapacity)
+		total   int
+		options RenderingOptions
+	)
+
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	for i := range entries {
+		var entry = PT(&entries[i])
+
+		// append line breaker only for non-first entries
+		if total > 0 {
+			buf = append(buf, "\n"...)
+		}
+
+		if options.Guard {
+			buf = entry.appendGuard(buf, options.Escape)
+		}
+
+		var formattingErr error
+
+		buf, formattingErr = entry.appendFormatted(buf, options.Prefix, options.Postfix, options.Escape)
+		if formattingErr == nil {
+			if options.Guard {
+				buf = append(buf, '}')
+			}
+
+			// write buffer
+			wrote, err := to.Write(buf)
+			if err != nil {
+				return total, err
+			}
+
+			total += wrote
+		}
+
+		// make buffer clean (capacity will keep maximum length)
+		buf = buf[:0]
+	}
+
+	return total, nil
+}